@@ -1,10 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/kelseyhightower/envconfig"
 )
 
 var (
@@ -12,33 +17,205 @@ var (
 )
 
 type Cold struct {
-	DB     DatabaseConfig
-	Server ServerConfig
+	DB      DatabaseConfig `yaml:"db"`
+	Server  ServerConfig   `yaml:"server"`
+	Auth    AuthConfig     `yaml:"auth"`
+	Storage StorageConfig  `yaml:"storage"`
+	Image   ImageConfig    `yaml:"image"`
 }
 
 type DatabaseConfig struct {
-	Host      string `env:"DB_HOST"`
-	Port      int    `env:"DB_PORT"`
-	User      string `env:"DB_USER"`
-	Password  string `env:"DB_PASSWORD"`
-	Name      string `env:"DB_NAME"`
-	Migration bool   `env:"DB_MIGRATION"`
+	Host      string `env:"DB_HOST" yaml:"host"`
+	Port      int    `env:"DB_PORT" yaml:"port" default:"5432"`
+	User      string `env:"DB_USER" yaml:"user"`
+	Password  string `env:"DB_PASSWORD" yaml:"password"`
+	Name      string `env:"DB_NAME" yaml:"name"`
+	Migration bool   `env:"DB_MIGRATION" yaml:"migration"`
+	// MigrationPath overrides the embedded migrations with a directory,
+	// for development.
+	MigrationPath string `env:"DB_MIGRATION_PATH" yaml:"migration_path"`
+	// DSN, when set, is used as the connection string instead of the
+	// individual fields above. Both URL and keyword/value forms are accepted.
+	DSN         string `env:"DB_DSN" yaml:"dsn"`
+	SSLMode     string `env:"DB_SSLMODE" yaml:"sslmode" default:"disable"`
+	SSLRootCert string `env:"DB_SSLROOTCERT" yaml:"sslrootcert"`
+	// StatementTimeout aborts any statement running longer than this. Zero
+	// leaves the server default in place.
+	StatementTimeout time.Duration `env:"DB_STATEMENTTIMEOUT" yaml:"statement_timeout"`
+	MaxOpenConns     int           `env:"DB_MAXOPENCONNS" yaml:"max_open_conns" default:"25"`
+	MaxIdleConns     int           `env:"DB_MAXIDLECONNS" yaml:"max_idle_conns" default:"5"`
+	ConnMaxLifetime  time.Duration `env:"DB_CONNMAXLIFETIME" yaml:"conn_max_lifetime" default:"30m"`
 }
 
 type ServerConfig struct {
-	Port string `env:"SERVER_PORT"`
-	Mode string `env:"GIN_MODE"`
+	Port string `env:"SERVER_PORT" yaml:"port"`
+	// Mode used to be read from SERVER_MODE; that name is still accepted
+	// when GIN_MODE is unset.
+	Mode string `env:"GIN_MODE,SERVER_MODE" yaml:"mode" default:"debug"`
+	// ShutdownTimeout bounds how long in-flight requests may run after a
+	// shutdown signal before the server closes them.
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWNTIMEOUT" yaml:"shutdown_timeout" default:"15s"`
 }
 
-func Init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+type AuthConfig struct {
+	Secret     string        `env:"AUTH_SECRET" yaml:"secret"`
+	Issuer     string        `env:"AUTH_ISSUER" yaml:"issuer" default:"growlink"`
+	AccessTTL  time.Duration `env:"AUTH_ACCESSTTL" yaml:"access_ttl" default:"15m"`
+	RefreshTTL time.Duration `env:"AUTH_REFRESHTTL" yaml:"refresh_ttl" default:"720h"`
+}
+
+type StorageConfig struct {
+	// Driver is "local" or "s3".
+	Driver string `env:"STORAGE_DRIVER" yaml:"driver" default:"local"`
+	// LocalDir is served by the API itself under the LocalURL path.
+	LocalDir string `env:"STORAGE_LOCAL_DIR" yaml:"local_dir" default:"./static"`
+	LocalURL string `env:"STORAGE_LOCAL_URL" yaml:"local_url" default:"/static"`
+	// S3Endpoint is the scheme and host of the S3-compatible service, e.g.
+	// https://s3.eu-west-1.amazonaws.com or http://localhost:9000.
+	S3Endpoint  string `env:"STORAGE_S3_ENDPOINT" yaml:"s3_endpoint"`
+	S3Region    string `env:"STORAGE_S3_REGION" yaml:"s3_region" default:"us-east-1"`
+	S3Bucket    string `env:"STORAGE_S3_BUCKET" yaml:"s3_bucket"`
+	S3AccessKey string `env:"STORAGE_S3_ACCESS_KEY" yaml:"s3_access_key"`
+	S3SecretKey string `env:"STORAGE_S3_SECRET_KEY" yaml:"s3_secret_key"`
+	S3PathStyle bool   `env:"STORAGE_S3_PATH_STYLE" yaml:"s3_path_style"`
+	// URLExpiry is how long presigned download URLs stay valid.
+	URLExpiry time.Duration `env:"STORAGE_URL_EXPIRY" yaml:"url_expiry" default:"1h"`
+}
+
+// ImageConfig sizes the variants generated for every uploaded image. Sizes
+// bound the longer side in pixels; the original variant keeps full size.
+type ImageConfig struct {
+	ThumbnailSize int `env:"IMAGE_THUMBNAIL_SIZE" yaml:"thumbnail_size" default:"200"`
+	MediumSize    int `env:"IMAGE_MEDIUM_SIZE" yaml:"medium_size" default:"800"`
+	JPEGQuality   int `env:"IMAGE_JPEG_QUALITY" yaml:"jpeg_quality" default:"85"`
+	// MaxConcurrent bounds how many images are decoded and re-encoded at
+	// once; each can take a few hundred MB at the largest allowed size.
+	MaxConcurrent int `env:"IMAGE_MAX_CONCURRENT" yaml:"max_concurrent" default:"2"`
+}
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// Validate reports every missing or malformed setting at once, so a
+// misconfigured deployment can be fixed in one pass. Settings only the HTTP
+// server needs are checked by ValidateServer.
+func (c Cold) Validate() error {
+	var errs []error
+
+	if c.DB.DSN == "" {
+		if c.DB.Host == "" {
+			errs = append(errs, errors.New("DB_HOST is required unless DB_DSN is set"))
+		}
+		if c.DB.Name == "" {
+			errs = append(errs, errors.New("DB_NAME is required unless DB_DSN is set"))
+		}
+		if c.DB.Port < 1 || c.DB.Port > 65535 {
+			errs = append(errs, fmt.Errorf("DB_PORT %d is not a valid port", c.DB.Port))
+		}
+		if !sslModes[c.DB.SSLMode] {
+			errs = append(errs, fmt.Errorf("DB_SSLMODE %q must be one of disable, require, verify-ca, verify-full", c.DB.SSLMode))
+		}
 	}
 
-	if err := envconfig.Process("", &CFG); err != nil {
-		log.Fatal("Error processing environment variables:", err)
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAXOPENCONNS and DB_MAXIDLECONNS must not be negative"))
 	}
 
-	log.Printf("Configuration loaded: %+v\n", CFG)
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			errs = append(errs, errors.New("STORAGE_LOCAL_DIR is required for the local driver"))
+		}
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" {
+			errs = append(errs, errors.New("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required for the s3 driver"))
+		}
+		if c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			errs = append(errs, errors.New("STORAGE_S3_ACCESS_KEY and STORAGE_S3_SECRET_KEY are required for the s3 driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER %q must be local or s3", c.Storage.Driver))
+	}
+
+	if c.Image.ThumbnailSize <= 0 || c.Image.MediumSize <= 0 {
+		errs = append(errs, errors.New("IMAGE_THUMBNAIL_SIZE and IMAGE_MEDIUM_SIZE must be positive"))
+	}
+	if c.Image.JPEGQuality < 1 || c.Image.JPEGQuality > 100 {
+		errs = append(errs, fmt.Errorf("IMAGE_JPEG_QUALITY %d must be between 1 and 100", c.Image.JPEGQuality))
+	}
+	if c.Image.MaxConcurrent < 1 {
+		errs = append(errs, errors.New("IMAGE_MAX_CONCURRENT must be positive"))
+	}
+
+	if c.Auth.AccessTTL <= 0 || c.Auth.RefreshTTL <= 0 {
+		errs = append(errs, errors.New("AUTH_ACCESSTTL and AUTH_REFRESHTTL must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ValidateServer reports the settings that must be present to serve HTTP,
+// which commands such as "growlink migrate" can do without.
+func (c Cold) ValidateServer() error {
+	var errs []error
+
+	if c.Server.Port == "" {
+		errs = append(errs, errors.New("SERVER_PORT is required"))
+	} else if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("SERVER_PORT %q is not a valid port", c.Server.Port))
+	}
+
+	if c.Auth.Secret == "" {
+		errs = append(errs, errors.New("AUTH_SECRET is required"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with secrets masked, for
+// logging.
+func (c Cold) Redacted() Cold {
+	if c.DB.Password != "" {
+		c.DB.Password = redacted
+	}
+	if c.DB.DSN != "" {
+		c.DB.DSN = RedactDSN(c.DB.DSN)
+	}
+	if c.Auth.Secret != "" {
+		c.Auth.Secret = redacted
+	}
+	if c.Storage.S3SecretKey != "" {
+		c.Storage.S3SecretKey = redacted
+	}
+	return c
+}
+
+// Init loads the configuration into CFG. A .env file is read if present but
+// is not required, so containers can inject the variables directly. The
+// optional YAML file named by CONFIG_FILE sits between the defaults and the
+// environment.
+func Init() error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading .env: %w", err)
+	}
+
+	cfg, err := Load(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+	if err != nil {
+		return err
+	}
+
+	CFG = cfg
+	log.Printf("Configuration loaded: %+v\n", CFG.Redacted())
+
+	return nil
 }
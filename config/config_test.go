@@ -0,0 +1,152 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// valid returns a configuration that passes Validate and ValidateServer.
+func valid() Cold {
+	cfg, err := Load("", env(map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_NAME":     "growlink",
+		"SERVER_PORT": "8080",
+		"AUTH_SECRET": "secret",
+	}))
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Cold)
+		want   []string
+	}{
+		{"valid", func(cfg *Cold) {}, nil},
+		{"dsn replaces fields", func(cfg *Cold) {
+			cfg.DB = DatabaseConfig{DSN: "postgres://localhost/growlink", SSLMode: "bogus", MaxOpenConns: 1}
+		}, nil},
+		{"server settings are not checked", func(cfg *Cold) {
+			cfg.Server.Port = ""
+			cfg.Auth.Secret = ""
+		}, nil},
+		{"every problem reported", func(cfg *Cold) {
+			cfg.DB.Host = ""
+			cfg.DB.Name = ""
+			cfg.DB.Port = 70000
+			cfg.DB.SSLMode = "bogus"
+			cfg.DB.MaxIdleConns = -1
+			cfg.Image.JPEGQuality = 0
+			cfg.Image.MaxConcurrent = 0
+			cfg.Auth.AccessTTL = 0
+		}, []string{
+			"DB_HOST is required",
+			"DB_NAME is required",
+			"DB_PORT 70000 is not a valid port",
+			`DB_SSLMODE "bogus"`,
+			"DB_MAXOPENCONNS and DB_MAXIDLECONNS must not be negative",
+			"IMAGE_JPEG_QUALITY 0 must be between 1 and 100",
+			"IMAGE_MAX_CONCURRENT must be positive",
+			"AUTH_ACCESSTTL and AUTH_REFRESHTTL must be positive",
+		}},
+		{"unknown storage driver", func(cfg *Cold) {
+			cfg.Storage.Driver = "ftp"
+		}, []string{`STORAGE_DRIVER "ftp" must be local or s3`}},
+		{"incomplete s3", func(cfg *Cold) {
+			cfg.Storage.Driver = "s3"
+			cfg.Storage.S3Endpoint = "http://localhost:9000"
+		}, []string{
+			"STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required",
+			"STORAGE_S3_ACCESS_KEY and STORAGE_S3_SECRET_KEY are required",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			checkErrors(t, cfg.Validate(), tt.want)
+		})
+	}
+}
+
+func TestValidateServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Cold)
+		want   []string
+	}{
+		{"valid", func(cfg *Cold) {}, nil},
+		{"missing", func(cfg *Cold) {
+			cfg.Server.Port = ""
+			cfg.Auth.Secret = ""
+		}, []string{"SERVER_PORT is required", "AUTH_SECRET is required"}},
+		{"port not a number", func(cfg *Cold) {
+			cfg.Server.Port = "http"
+		}, []string{`SERVER_PORT "http" is not a valid port`}},
+		{"port out of range", func(cfg *Cold) {
+			cfg.Server.Port = "65536"
+		}, []string{`SERVER_PORT "65536" is not a valid port`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			checkErrors(t, cfg.ValidateServer(), tt.want)
+		})
+	}
+}
+
+// checkErrors asserts that err lists exactly the wanted problems, one per
+// line after the heading.
+func checkErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("error = nil, want %q", want)
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if lines[0] != "invalid configuration:" || len(lines)-1 != len(want) {
+		t.Fatalf("error = %q, want %d problems", err, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i+1], w) {
+			t.Errorf("problem %d = %q, want it to contain %q", i, lines[i+1], w)
+		}
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{"url password", "postgres://app:hunter2@db:5432/growlink?sslmode=require", "postgres://app:xxxxx@db:5432/growlink?sslmode=require"},
+		{"postgresql scheme", "postgresql://app:hunter2@db/growlink", "postgresql://app:xxxxx@db/growlink"},
+		{"url password parameter", "postgres://db/growlink?password=hunter2&user=app", "postgres://db/growlink?password=xxxxx&user=app"},
+		{"url without password", "postgres://app@db/growlink", "postgres://app@db/growlink"},
+		{"keyword value", "host=db user=app password=hunter2 dbname=growlink", "host=db user=app password=xxxxx dbname=growlink"},
+		{"spaced keyword value", "host=db password = hunter2 dbname=growlink", "host=db password = xxxxx dbname=growlink"},
+		{"quoted keyword value", `host=db password='hun ter\'2' dbname=growlink`, "host=db password=xxxxx dbname=growlink"},
+		{"no password", "host=db dbname=growlink", "host=db dbname=growlink"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactDSN(tt.dsn); got != tt.want {
+				t.Errorf("RedactDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+			}
+		})
+	}
+}
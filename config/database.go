@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "xxxxx"
+
+// ConnString builds the lib/pq connection string. An explicit DSN wins over
+// the individual fields; the statement timeout is applied to either form.
+func (c DatabaseConfig) ConnString() string {
+	timeout := ""
+	if c.StatementTimeout > 0 {
+		timeout = fmt.Sprint(c.StatementTimeout.Milliseconds())
+	}
+
+	if c.DSN != "" {
+		if timeout == "" {
+			return c.DSN
+		}
+		if u, err := url.Parse(c.DSN); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+			query := u.Query()
+			query.Set("statement_timeout", timeout)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+		return c.DSN + " statement_timeout=" + timeout
+	}
+
+	params := []string{
+		"host=" + quote(c.Host),
+		fmt.Sprintf("port=%d", c.Port),
+		"user=" + quote(c.User),
+		"password=" + quote(c.Password),
+		"dbname=" + quote(c.Name),
+		"sslmode=" + quote(c.SSLMode),
+	}
+	if c.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+quote(c.SSLRootCert))
+	}
+	if timeout != "" {
+		params = append(params, "statement_timeout="+timeout)
+	}
+
+	return strings.Join(params, " ")
+}
+
+// quote wraps a keyword/value connection parameter in single quotes when it
+// is empty or contains characters that would otherwise end the value.
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+var passwordParam = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// RedactDSN masks the password in a connection string so it can be logged.
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		if u.Query().Has("password") {
+			query := u.Query()
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	}
+	return passwordParam.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LookupFunc reports the value of a variable and whether it is set, like
+// os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load builds a configuration from three layers, each overriding the last:
+// the `default` struct tags, the YAML file at path (skipped when path is
+// empty) and the variables named by the `env` tags. An `env` tag may list
+// former names after the current one, separated by commas; they are only
+// read when the current name is unset. The result is validated before it is
+// returned.
+func Load(path string, lookup LookupFunc) (Cold, error) {
+	var cfg Cold
+
+	if err := walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) error {
+		if value, ok := tag.Lookup("default"); ok {
+			return setField(field, value)
+		}
+		return nil
+	}); err != nil {
+		return cfg, err
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	if err := walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) error {
+		keys := tag.Get("env")
+		if keys == "" {
+			return nil
+		}
+		names := strings.Split(keys, ",")
+		for i, key := range names {
+			value, ok := lookup(key)
+			if !ok {
+				continue
+			}
+			if i > 0 {
+				log.Printf("%s is deprecated, use %s instead", key, names[0])
+			}
+			if err := setField(field, value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			return nil
+		}
+		return nil
+	}); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// walk calls fn for every leaf field of the struct v, descending into nested
+// structs.
+func walk(v reflect.Value, fn func(field reflect.Value, tag reflect.StructTag) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := walk(field, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, t.Field(i).Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
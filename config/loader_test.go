@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a LookupFunc over a fixed set of variables.
+func env(vars map[string]string) LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayering(t *testing.T) {
+	path := writeFile(t, `
+db:
+  host: yaml-host
+  name: yaml-name
+  port: 6543
+server:
+  port: "8080"
+image:
+  jpeg_quality: 70
+`)
+
+	tests := []struct {
+		name  string
+		path  string
+		env   map[string]string
+		check func(t *testing.T, cfg Cold)
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"DB_HOST": "env-host", "DB_NAME": "env-name"},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "DB.Port", cfg.DB.Port, 5432)
+				expect(t, "DB.SSLMode", cfg.DB.SSLMode, "disable")
+				expect(t, "DB.ConnMaxLifetime", cfg.DB.ConnMaxLifetime, 30*time.Minute)
+				expect(t, "Storage.Driver", cfg.Storage.Driver, "local")
+				expect(t, "Image.JPEGQuality", cfg.Image.JPEGQuality, 85)
+			},
+		},
+		{
+			name: "yaml over defaults",
+			path: path,
+			env:  map[string]string{},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "DB.Host", cfg.DB.Host, "yaml-host")
+				expect(t, "DB.Port", cfg.DB.Port, 6543)
+				expect(t, "Image.JPEGQuality", cfg.Image.JPEGQuality, 70)
+				expect(t, "DB.SSLMode", cfg.DB.SSLMode, "disable")
+			},
+		},
+		{
+			name: "env over yaml",
+			path: path,
+			env:  map[string]string{"DB_HOST": "env-host", "DB_PORT": "7654", "DB_CONNMAXLIFETIME": "1h", "DB_MIGRATION": "true"},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "DB.Host", cfg.DB.Host, "env-host")
+				expect(t, "DB.Port", cfg.DB.Port, 7654)
+				expect(t, "DB.Name", cfg.DB.Name, "yaml-name")
+				expect(t, "DB.ConnMaxLifetime", cfg.DB.ConnMaxLifetime, time.Hour)
+				expect(t, "DB.Migration", cfg.DB.Migration, true)
+				expect(t, "Image.JPEGQuality", cfg.Image.JPEGQuality, 70)
+			},
+		},
+		{
+			name: "empty env value overrides",
+			path: path,
+			env:  map[string]string{"SERVER_PORT": ""},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "Server.Port", cfg.Server.Port, "")
+			},
+		},
+		{
+			name: "former env name",
+			path: path,
+			env:  map[string]string{"SERVER_MODE": "release"},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "Server.Mode", cfg.Server.Mode, "release")
+			},
+		},
+		{
+			name: "current env name over former",
+			path: path,
+			env:  map[string]string{"GIN_MODE": "test", "SERVER_MODE": "release"},
+			check: func(t *testing.T, cfg Cold) {
+				expect(t, "Server.Mode", cfg.Server.Mode, "test")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(tt.path, env(tt.env))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		env  map[string]string
+		want string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.yaml"), nil, "reading config file"},
+		{"malformed yaml", writeFile(t, "db: [\n"), nil, "parsing config file"},
+		{"bad integer", "", map[string]string{"DB_PORT": "five"}, `DB_PORT: invalid integer "five"`},
+		{"bad duration", "", map[string]string{"AUTH_ACCESSTTL": "soon"}, `AUTH_ACCESSTTL: invalid duration "soon"`},
+		{"bad boolean", "", map[string]string{"DB_MIGRATION": "maybe"}, `DB_MIGRATION: invalid boolean "maybe"`},
+		{"invalid result", "", map[string]string{}, "DB_HOST is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.path, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func expect[T comparable](t *testing.T, field string, got, want T) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
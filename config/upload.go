@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Upload purposes. Every file is uploaded for one of these, and the purpose
+// decides which types and sizes are accepted and where the file may be
+// attached.
+const (
+	PurposeImage        = "image"
+	PurposeResume       = "resume"
+	PurposePortfolio    = "portfolio"
+	PurposeProjectBrief = "project_brief"
+)
+
+// Attachment targets.
+const (
+	AttachStudent = "student"
+	AttachProject = "project"
+)
+
+// MaxSimpleUploadSize caps single-request multipart uploads. Larger files
+// go through the resumable upload endpoints.
+const MaxSimpleUploadSize = 25 << 20
+
+// Resumable uploads arrive in chunks of MinChunkSize to MaxChunkSize bytes;
+// only the last chunk may be smaller. Uploads that see no chunk for
+// UploadExpiry are removed together with their chunks.
+const (
+	MinChunkSize    = 1 << 20
+	MaxChunkSize    = 64 << 20
+	MaxUploadChunks = 1000
+	UploadExpiry    = 24 * time.Hour
+)
+
+// MaxImageSize caps image uploads whatever their purpose, since images are
+// decoded and re-encoded in memory. Other types are validated while they
+// stream to storage and are only bound by their policy.
+const MaxImageSize = 25 << 20
+
+// UploadPolicy limits what may be uploaded for one purpose.
+type UploadPolicy struct {
+	// MimeTypes are matched against the type sniffed from the content.
+	MimeTypes []string
+	MaxSize   int64
+	AttachTo  []string
+}
+
+// MaxSizeFor returns the size limit for a file of the given sniffed type.
+func (p UploadPolicy) MaxSizeFor(mimeType string) int64 {
+	if strings.HasPrefix(mimeType, "image/") {
+		return min(p.MaxSize, MaxImageSize)
+	}
+	return p.MaxSize
+}
+
+var imageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// UploadPolicies is the single place upload rules are defined.
+var UploadPolicies = map[string]UploadPolicy{
+	PurposeImage: {
+		MimeTypes: imageTypes,
+		MaxSize:   10 << 20,
+		AttachTo:  []string{AttachStudent, AttachProject},
+	},
+	PurposeResume: {
+		MimeTypes: []string{"application/pdf"},
+		MaxSize:   5 << 20,
+		AttachTo:  []string{AttachStudent},
+	},
+	PurposePortfolio: {
+		MimeTypes: append([]string{"application/pdf", "video/mp4", "video/webm", "application/zip"}, imageTypes...),
+		MaxSize:   500 << 20,
+		AttachTo:  []string{AttachStudent},
+	},
+	PurposeProjectBrief: {
+		MimeTypes: []string{"application/pdf"},
+		MaxSize:   10 << 20,
+		AttachTo:  []string{AttachProject},
+	},
+}
@@ -0,0 +1,12 @@
+package config
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X github.com/HPNV/growlink-backend/config.Version=v1.2.0 \
+//		-X github.com/HPNV/growlink-backend/config.Commit=$(git rev-parse HEAD) \
+//		-X github.com/HPNV/growlink-backend/config.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
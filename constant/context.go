@@ -0,0 +1,10 @@
+package constant
+
+// Keys under which the authentication middleware stores the caller's
+// identity in gin.Context.
+const (
+	ContextUserUUID     = "user_uuid"
+	ContextRole         = "role"
+	ContextStudentUUID  = "student_uuid"
+	ContextBusinessUUID = "business_uuid"
+)
@@ -1,9 +1,231 @@
 package constant
 
-import "errors"
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Kind classifies an Error so the delivery layer can pick an HTTP status
+// without knowing about individual failures.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is the application error type. Code is a stable machine readable
+// identifier, Message is safe to show to API clients and Err keeps the
+// underlying cause for logging.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code, so a freshly built error still satisfies
+// errors.Is against the package level sentinels below.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return newError(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return newError(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+// InvalidRequest wraps a request binding or parsing failure.
+func InvalidRequest(err error) *Error {
+	return &Error{Kind: KindValidation, Code: "invalid_request", Message: err.Error(), Err: err}
+}
+
+// UnknownSkill is returned when a request references a skill name that does
+// not exist.
+func UnknownSkill(name string) *Error {
+	return Validation("unknown_skill", fmt.Sprintf("unknown skill %q", name))
+}
+
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error", Err: err}
+}
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserNotFound       = errors.New("user not found")
-	ErrEmailTaken         = errors.New("email already taken")
+	ErrInvalidCredentials  = Unauthorized("invalid_credentials", "invalid credentials")
+	ErrUserNotFound        = NotFound("user_not_found", "user not found")
+	ErrEmailTaken          = Conflict("email_taken", "email already taken")
+	ErrInvalidToken        = Unauthorized("invalid_token", "invalid or expired token")
+	ErrInvalidRefreshToken = Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	ErrUnauthenticated     = Unauthorized("unauthenticated", "authentication required")
+	ErrForbidden           = Forbidden("forbidden", "you are not allowed to perform this action")
+	ErrProjectNotOpen      = Conflict("project_not_open", "project is not accepting applications")
+	ErrAlreadyApplied      = Conflict("already_applied", "student already has an active application for this project")
+	ErrInvalidTransition   = Conflict("invalid_status_transition", "invalid status transition")
+	ErrInvalidAttachment   = Validation("invalid_attachment", "attachment does not exist or does not belong to the applicant")
+	ErrAlreadyEndorsed     = Conflict("already_endorsed", "skill was already endorsed for this project")
+	ErrProjectNotCompleted = Conflict("project_not_completed", "endorsements can only be given for completed projects")
+	ErrNotProjectMember    = Validation("not_project_member", "student did not work on this project")
+	ErrSkillNotListed      = Validation("skill_not_listed", "student does not list this skill")
+	ErrInvalidFileType     = Validation("invalid_file_type", "file type is not allowed")
+	ErrInvalidPurpose      = Validation("invalid_purpose", "unknown upload purpose")
+	ErrFileTooLarge        = Validation("file_too_large", "file is too large")
+	ErrCorruptDocument     = Validation("corrupt_document", "document is corrupt or truncated")
+	ErrFileNotOwned        = Validation("file_not_owned", "file does not exist or does not belong to the owner")
+	ErrNotAttachable       = Validation("not_attachable", "files with this purpose cannot be attached here")
+	ErrCorruptImage        = Validation("corrupt_image", "image is corrupt or truncated")
+	ErrImageTooLarge       = Validation("image_too_large", "image dimensions exceed the allowed maximum")
+	ErrFileIsVariant       = Conflict("file_is_variant", "image variants are deleted together with their upload")
+	ErrUploadOffset        = Conflict("upload_offset_mismatch", "upload offset does not match the bytes received so far")
+	ErrUploadComplete      = Conflict("upload_complete", "upload has already been completed")
+	ErrUploadLength        = Validation("upload_length_exceeded", "chunk would exceed the declared upload length")
+	ErrChunkSize           = Validation("invalid_chunk_size", "chunk size is out of range")
+	ErrTooManyChunks       = Validation("too_many_chunks", "upload has too many chunks")
+)
+
+// InvalidTransition reports a status change that the entity's state machine
+// does not allow. It matches ErrInvalidTransition with errors.Is.
+func InvalidTransition(entity, from, to string) *Error {
+	err := Conflict(ErrInvalidTransition.Code, fmt.Sprintf("cannot move %s from %q to %q", entity, from, to))
+	err.Details = map[string]string{"entity": entity, "from": from, "to": to}
+	return err
+}
+
+// InvalidFileType reports an upload whose sniffed content type is not one of
+// the allowed types. It matches ErrInvalidFileType with errors.Is.
+func InvalidFileType(detected string, allowed []string) *Error {
+	err := Validation(ErrInvalidFileType.Code, fmt.Sprintf("file type %s is not allowed; expected one of %s", detected, strings.Join(allowed, ", ")))
+	err.Details = map[string]interface{}{"detected": detected, "allowed": allowed}
+	return err
+}
+
+// InvalidPurpose reports an unknown upload purpose. It matches
+// ErrInvalidPurpose with errors.Is.
+func InvalidPurpose(purpose string, allowed []string) *Error {
+	err := Validation(ErrInvalidPurpose.Code, fmt.Sprintf("unknown upload purpose %q; expected one of %s", purpose, strings.Join(allowed, ", ")))
+	err.Details = map[string]interface{}{"allowed": allowed}
+	return err
+}
+
+// FileTooLarge reports an upload over its purpose's size cap. It matches
+// ErrFileTooLarge with errors.Is.
+func FileTooLarge(size, maxSize int64) *Error {
+	err := Validation(ErrFileTooLarge.Code, fmt.Sprintf("file is %d bytes; at most %d bytes are allowed", size, maxSize))
+	err.Details = map[string]int64{"size": size, "max_size": maxSize}
+	return err
+}
+
+// ImageTooLarge reports an image whose dimensions exceed the limits. It
+// matches ErrImageTooLarge with errors.Is.
+func ImageTooLarge(width, height, maxSide, maxPixels int) *Error {
+	err := Validation(ErrImageTooLarge.Code, fmt.Sprintf("image is %dx%d; at most %d pixels per side and %d pixels in total are allowed", width, height, maxSide, maxPixels))
+	err.Details = map[string]int{"width": width, "height": height, "max_side": maxSide, "max_pixels": maxPixels}
+	return err
+}
+
+// UploadOffsetMismatch reports a resumable upload chunk sent for an offset
+// other than the one the server is at. It matches ErrUploadOffset with
+// errors.Is.
+func UploadOffsetMismatch(offset, expected int64) *Error {
+	err := Conflict(ErrUploadOffset.Code, fmt.Sprintf("chunk starts at offset %d but the upload is at offset %d", offset, expected))
+	err.Details = map[string]int64{"offset": offset, "expected": expected}
+	return err
+}
+
+// ChunkSize reports a resumable upload chunk outside the allowed size range.
+// It matches ErrChunkSize with errors.Is.
+func ChunkSize(size, minSize, maxSize int64) *Error {
+	err := Validation(ErrChunkSize.Code, fmt.Sprintf("chunk is %d bytes; chunks other than the last must be %d to %d bytes", size, minSize, maxSize))
+	err.Details = map[string]int64{"size": size, "min_size": minSize, "max_size": maxSize}
+	return err
+}
+
+// Postgres error codes translated by FromDB.
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+	pqInvalidText         = "22P02"
 )
+
+// FromDB converts database driver errors into application errors for the
+// given entity name. Unknown errors are returned unchanged and end up as
+// internal errors.
+func FromDB(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	code := strings.ReplaceAll(entity, " ", "_")
+
+	if errors.Is(err, sql.ErrNoRows) {
+		e := NotFound(code+"_not_found", entity+" not found")
+		e.Err = err
+		return e
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var e *Error
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		e = Conflict(code+"_already_exists", entity+" already exists")
+	case pqForeignKeyViolation:
+		e = Validation("invalid_reference", "referenced record does not exist")
+	case pqCheckViolation:
+		e = Validation("invalid_value", "value is not allowed for "+entity)
+	case pqInvalidText:
+		e = Validation("invalid_input", "malformed identifier or value")
+	default:
+		return err
+	}
+	e.Err = err
+	return e
+}
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
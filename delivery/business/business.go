@@ -1,8 +1,10 @@
 package business
 
 import (
+	"log"
 	"net/http"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
@@ -30,32 +32,39 @@ func NewBusiness(service service.IRegistry) IBusiness {
 func (b *Business) Create(c *gin.Context) {
 	var req dto.BusinessRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
 	// Get user UUID from context (would be set by auth middleware)
-	userUUID, exists := c.Get("user_uuid")
+	userUUID, exists := c.Get(constant.ContextUserUUID)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(constant.ErrUnauthenticated)
 		return
 	}
 
-	business, err := b.service.GetBusiness().Create(userUUID.(string), &req)
+	business, err := b.service.GetBusiness().Create(c.Request.Context(), userUUID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	// The caller's access token predates the business; without new tokens
+	// the client has to refresh before it can manage it
+	business.Tokens, err = b.service.GetUser().IssueTokens(c.Request.Context(), userUUID.(string))
+	if err != nil {
+		log.Printf("Failed to issue tokens for new business %s: %v", business.UUID, err)
+	}
+
 	c.JSON(http.StatusCreated, business)
 }
 
 func (b *Business) GetByUUID(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	business, err := b.service.GetBusiness().GetByUUID(uuid)
+	business, err := b.service.GetBusiness().GetByUUID(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Business not found"})
+		c.Error(err)
 		return
 	}
 
@@ -65,9 +74,9 @@ func (b *Business) GetByUUID(c *gin.Context) {
 func (b *Business) GetByUserUUID(c *gin.Context) {
 	userUUID := c.Param("userUuid")
 
-	business, err := b.service.GetBusiness().GetByUserUUID(userUUID)
+	business, err := b.service.GetBusiness().GetByUserUUID(c.Request.Context(), userUUID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Business not found"})
+		c.Error(err)
 		return
 	}
 
@@ -79,13 +88,13 @@ func (b *Business) Update(c *gin.Context) {
 	var req dto.BusinessRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	business, err := b.service.GetBusiness().Update(uuid, &req)
+	business, err := b.service.GetBusiness().Update(c.Request.Context(), uuid, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -95,9 +104,9 @@ func (b *Business) Update(c *gin.Context) {
 func (b *Business) Delete(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	err := b.service.GetBusiness().Delete(uuid)
+	err := b.service.GetBusiness().Delete(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -105,9 +114,9 @@ func (b *Business) Delete(c *gin.Context) {
 }
 
 func (b *Business) GetAll(c *gin.Context) {
-	businesses, err := b.service.GetBusiness().GetAll()
+	businesses, err := b.service.GetBusiness().GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -3,15 +3,21 @@ package file
 import (
 	"net/http"
 
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
 )
 
 type IFile interface {
-	UploadImage(c *gin.Context)
+	Upload(c *gin.Context)
 	GetByUUID(c *gin.Context)
 	Delete(c *gin.Context)
 	GetByUploadedBy(c *gin.Context)
+	CreateUpload(c *gin.Context)
+	HeadUpload(c *gin.Context)
+	PatchUpload(c *gin.Context)
+	DeleteUpload(c *gin.Context)
 }
 
 type File struct {
@@ -24,37 +30,27 @@ func NewFile(service service.IRegistry) IFile {
 	}
 }
 
-func (f *File) UploadImage(c *gin.Context) {
+// Upload accepts a multipart "file" field, or "image" from older clients,
+// and a "purpose" field that defaults to image.
+func (f *File) Upload(c *gin.Context) {
 	// Get file from form
-	file, header, err := c.Request.FormFile("image")
+	file, header, err := c.Request.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		file, header, err = c.Request.FormFile("image")
+	}
+	if err != nil {
+		c.Error(constant.Validation("file_required", "No file uploaded"))
 		return
 	}
 
-	// Get uploaded_by from query param or authenticated user
-	uploadedBy := c.Query("uploaded_by")
-	if uploadedBy == "" {
-		// Could get from auth middleware context
-		userUUID, exists := c.Get("user_uuid")
-		if exists {
-			uploadedBy = userUUID.(string)
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "uploaded_by parameter required"})
-			return
-		}
-	}
+	purpose := c.DefaultPostForm("purpose", config.PurposeImage)
 
-	// Validate file size (10MB limit)
-	const maxFileSize = 10 << 20 // 10MB
-	if header.Size > maxFileSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File size too large. Maximum 10MB allowed"})
-		return
-	}
+	// Files always belong to the caller; ownership checks rely on it
+	uploadedBy := c.GetString(constant.ContextUserUUID)
 
-	result, err := f.service.GetFile().UploadImage(file, header, uploadedBy)
+	result, err := f.service.GetFile().Upload(c.Request.Context(), file, header, uploadedBy, purpose)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -64,9 +60,9 @@ func (f *File) UploadImage(c *gin.Context) {
 func (f *File) GetByUUID(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	file, err := f.service.GetFile().GetByUUID(uuid)
+	file, err := f.service.GetFile().GetByUUID(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		c.Error(err)
 		return
 	}
 
@@ -76,9 +72,9 @@ func (f *File) GetByUUID(c *gin.Context) {
 func (f *File) Delete(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	err := f.service.GetFile().Delete(uuid)
+	err := f.service.GetFile().Delete(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -88,9 +84,9 @@ func (f *File) Delete(c *gin.Context) {
 func (f *File) GetByUploadedBy(c *gin.Context) {
 	uploadedBy := c.Param("uploadedBy")
 
-	files, err := f.service.GetFile().GetByUploadedBy(uploadedBy)
+	files, err := f.service.GetFile().GetByUploadedBy(c.Request.Context(), uploadedBy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
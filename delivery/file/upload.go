@@ -0,0 +1,144 @@
+package file
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/gin-gonic/gin"
+)
+
+// The resumable upload endpoints follow the core of the tus protocol, so tus
+// clients can drive them: POST creates an upload, HEAD reports how much has
+// arrived and PATCH appends the next chunk at that offset.
+const (
+	tusVersion       = "1.0.0"
+	chunkContentType = "application/offset+octet-stream"
+)
+
+// CreateUpload starts a resumable upload. Upload-Length gives the total size
+// and Upload-Metadata carries the base64 encoded "filename" and "purpose".
+func (f *File) CreateUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		c.Error(constant.Validation("invalid_upload_length", "Upload-Length header must be a number"))
+		return
+	}
+
+	metadata, err := parseMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.Error(constant.Validation("invalid_upload_metadata", "Upload-Metadata values must be base64 encoded"))
+		return
+	}
+	if metadata["filename"] == "" {
+		c.Error(constant.Validation("filename_required", "Upload-Metadata must include a filename"))
+		return
+	}
+
+	purpose := metadata["purpose"]
+	if purpose == "" {
+		purpose = config.PurposeImage
+	}
+
+	uploadedBy := c.GetString(constant.ContextUserUUID)
+
+	result, err := f.service.GetFile().CreateUpload(c.Request.Context(), uploadedBy, purpose, metadata["filename"], length)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+result.UUID)
+	c.Header("Upload-Offset", "0")
+	c.JSON(http.StatusCreated, result)
+}
+
+// HeadUpload reports the offset a client should resume from.
+func (f *File) HeadUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Cache-Control", "no-store")
+
+	result, err := f.service.GetFile().GetUpload(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(result.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(result.Length, 10))
+	c.Status(http.StatusOK)
+}
+
+// PatchUpload appends the request body at Upload-Offset. It answers 204 while
+// bytes are missing and 200 with the stored file once the upload is complete.
+func (f *File) PatchUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	if c.ContentType() != chunkContentType {
+		c.Error(constant.Validation("invalid_content_type", "Content-Type must be "+chunkContentType))
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.Error(constant.Validation("invalid_upload_offset", "Upload-Offset header must be a non-negative number"))
+		return
+	}
+
+	// Chunks are stored with their size, so it has to be known up front
+	size := c.Request.ContentLength
+	if size < 0 {
+		c.Error(constant.Validation("content_length_required", "Content-Length header required"))
+		return
+	}
+
+	result, err := f.service.GetFile().PatchUpload(c.Request.Context(), c.Param("uuid"), offset, c.Request.Body, size)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(result.Offset, 10))
+	if result.File == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (f *File) DeleteUpload(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+
+	err := f.service.GetFile().DeleteUpload(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseMetadata decodes an Upload-Metadata header: comma separated pairs of
+// a key and an optional base64 value.
+func parseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
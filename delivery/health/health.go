@@ -0,0 +1,48 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/HPNV/growlink-backend/service"
+	"github.com/gin-gonic/gin"
+)
+
+type IHealth interface {
+	Healthz(c *gin.Context)
+	Readyz(c *gin.Context)
+	Version(c *gin.Context)
+}
+
+type Health struct {
+	service service.IRegistry
+}
+
+func NewHealth(service service.IRegistry) IHealth {
+	return &Health{
+		service: service,
+	}
+}
+
+// Healthz reports that the process is up and serving requests. It checks no
+// dependencies, so a failing database never gets the process restarted.
+func (h *Health) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponse{Status: dto.HealthStatusOK})
+}
+
+// Readyz reports whether the service can take traffic, answering 503 with the
+// failing checks when it cannot.
+func (h *Health) Readyz(c *gin.Context) {
+	response := h.service.GetHealth().Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if response.Status != dto.HealthStatusReady {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, response)
+}
+
+func (h *Health) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.GetHealth().Version())
+}
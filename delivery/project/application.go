@@ -0,0 +1,83 @@
+package project
+
+import (
+	"net/http"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func (p *Project) Apply(c *gin.Context) {
+	projectUUID := c.Param("uuid")
+
+	studentUUID := c.GetString(constant.ContextStudentUUID)
+	if studentUUID == "" {
+		c.Error(constant.Forbidden("students_only", "Only students can apply to projects"))
+		return
+	}
+
+	var req dto.ApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	application, err := p.service.GetProject().Apply(c.Request.Context(), projectUUID, studentUUID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, application)
+}
+
+func (p *Project) Withdraw(c *gin.Context) {
+	application, err := p.service.GetProject().Withdraw(c.Request.Context(), c.Param("uuid"), c.Param("applicationUuid"), c.GetString(constant.ContextStudentUUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (p *Project) Shortlist(c *gin.Context) {
+	application, err := p.service.GetProject().Shortlist(c.Request.Context(), c.Param("uuid"), c.Param("applicationUuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (p *Project) Accept(c *gin.Context) {
+	application, err := p.service.GetProject().Accept(c.Request.Context(), c.Param("uuid"), c.Param("applicationUuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (p *Project) Reject(c *gin.Context) {
+	application, err := p.service.GetProject().Reject(c.Request.Context(), c.Param("uuid"), c.Param("applicationUuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (p *Project) GetApplications(c *gin.Context) {
+	applications, err := p.service.GetProject().GetApplications(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
@@ -3,6 +3,7 @@ package project
 import (
 	"net/http"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
@@ -19,9 +20,19 @@ type IProject interface {
 	AddSkill(c *gin.Context)
 	RemoveSkill(c *gin.Context)
 	GetSkills(c *gin.Context)
-	AddStudent(c *gin.Context)
 	RemoveStudent(c *gin.Context)
 	GetStudents(c *gin.Context)
+	Apply(c *gin.Context)
+	Withdraw(c *gin.Context)
+	Shortlist(c *gin.Context)
+	Accept(c *gin.Context)
+	Reject(c *gin.Context)
+	GetApplications(c *gin.Context)
+	GetStatusHistory(c *gin.Context)
+	GetRecommendedStudents(c *gin.Context)
+	GetFiles(c *gin.Context)
+	AttachFile(c *gin.Context)
+	DetachFile(c *gin.Context)
 }
 
 type Project struct {
@@ -37,7 +48,7 @@ func NewProject(service service.IRegistry) IProject {
 func (p *Project) Create(c *gin.Context) {
 	var req dto.ProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
@@ -45,13 +56,13 @@ func (p *Project) Create(c *gin.Context) {
 	businessUUID := c.Param("businessUuid")
 	if businessUUID == "" {
 		// Could also get from authenticated user context
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Business UUID required"})
+		c.Error(constant.Validation("business_uuid_required", "Business UUID required"))
 		return
 	}
 
-	project, err := p.service.GetProject().Create(businessUUID, &req)
+	project, err := p.service.GetProject().Create(c.Request.Context(), businessUUID, c.GetString(constant.ContextUserUUID), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -61,9 +72,9 @@ func (p *Project) Create(c *gin.Context) {
 func (p *Project) GetByUUID(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	project, err := p.service.GetProject().GetByUUID(uuid)
+	project, err := p.service.GetProject().GetByUUID(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		c.Error(err)
 		return
 	}
 
@@ -75,13 +86,13 @@ func (p *Project) Update(c *gin.Context) {
 	var req dto.ProjectUpdateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	project, err := p.service.GetProject().Update(uuid, &req)
+	project, err := p.service.GetProject().Update(c.Request.Context(), uuid, c.GetString(constant.ContextUserUUID), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -91,9 +102,9 @@ func (p *Project) Update(c *gin.Context) {
 func (p *Project) Delete(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	err := p.service.GetProject().Delete(uuid)
+	err := p.service.GetProject().Delete(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -101,9 +112,9 @@ func (p *Project) Delete(c *gin.Context) {
 }
 
 func (p *Project) GetAll(c *gin.Context) {
-	projects, err := p.service.GetProject().GetAll()
+	projects, err := p.service.GetProject().GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -122,6 +133,8 @@ func (p *Project) GetAllList(c *gin.Context) {
 		req.Search = &search
 	}
 
+	req.Sort = c.Query("sort")
+
 	// Parse budget (optional)
 	if budget := c.Query("budget"); budget != "" {
 		if budgetVal, err := parseIntFromString(budget); err == nil {
@@ -138,17 +151,21 @@ func (p *Project) GetAllList(c *gin.Context) {
 	}
 	req.Page = page
 
-	limit := 10
-	if limitStr := c.DefaultQuery("limit", "10"); limitStr != "" {
-		if limitInt, err := parseIntFromString(limitStr); err == nil {
-			limit = limitInt
-		}
+	limit, err := parseIntFromString(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		c.Error(constant.Validation("invalid_limit", "limit must be a positive number"))
+		return
 	}
-	req.Limit = limit
+	req.Limit = min(limit, dto.MaxPageLimit)
 
-	response, err := p.service.GetProject().GetAllList(&req)
+	// Presence of the cursor parameter, even empty, selects keyset pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		req.Cursor = &cursor
+	}
+
+	response, err := p.service.GetProject().GetAllList(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -170,9 +187,9 @@ func parseIntFromString(s string) (int, error) {
 func (p *Project) GetByBusinessUUID(c *gin.Context) {
 	businessUUID := c.Param("businessUuid")
 
-	projects, err := p.service.GetProject().GetByBusinessUUID(businessUUID)
+	projects, err := p.service.GetProject().GetByBusinessUUID(c.Request.Context(), businessUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -184,13 +201,13 @@ func (p *Project) AddSkill(c *gin.Context) {
 
 	var req dto.SkillNameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	err := p.service.GetProject().AddSkill(projectUUID, req.SkillName)
+	err := p.service.GetProject().AddSkill(c.Request.Context(), projectUUID, req.SkillName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -202,13 +219,13 @@ func (p *Project) RemoveSkill(c *gin.Context) {
 
 	var req dto.SkillNameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	err := p.service.GetProject().RemoveSkill(projectUUID, req.SkillName)
+	err := p.service.GetProject().RemoveSkill(c.Request.Context(), projectUUID, req.SkillName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -218,49 +235,109 @@ func (p *Project) RemoveSkill(c *gin.Context) {
 func (p *Project) GetSkills(c *gin.Context) {
 	projectUUID := c.Param("uuid")
 
-	skills, err := p.service.GetProject().GetSkills(projectUUID)
+	skills, err := p.service.GetProject().GetSkills(c.Request.Context(), projectUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, skills)
 }
 
-func (p *Project) AddStudent(c *gin.Context) {
+func (p *Project) RemoveStudent(c *gin.Context) {
 	projectUUID := c.Param("uuid")
 	studentUUID := c.Param("studentUuid")
 
-	err := p.service.GetProject().AddStudent(projectUUID, studentUUID)
+	err := p.service.GetProject().RemoveStudent(c.Request.Context(), projectUUID, studentUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Student added to project successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Student removed from project successfully"})
 }
 
-func (p *Project) RemoveStudent(c *gin.Context) {
+func (p *Project) GetStudents(c *gin.Context) {
 	projectUUID := c.Param("uuid")
-	studentUUID := c.Param("studentUuid")
 
-	err := p.service.GetProject().RemoveStudent(projectUUID, studentUUID)
+	students, err := p.service.GetProject().GetStudents(c.Request.Context(), projectUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Student removed from project successfully"})
+	c.JSON(http.StatusOK, students)
 }
 
-func (p *Project) GetStudents(c *gin.Context) {
+func (p *Project) GetStatusHistory(c *gin.Context) {
 	projectUUID := c.Param("uuid")
 
-	students, err := p.service.GetProject().GetStudents(projectUUID)
+	history, err := p.service.GetProject().GetStatusHistory(c.Request.Context(), projectUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, students)
+	c.JSON(http.StatusOK, history)
+}
+
+func (p *Project) GetRecommendedStudents(c *gin.Context) {
+	projectUUID := c.Param("uuid")
+
+	limit, err := parseIntFromString(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.Error(constant.Validation("invalid_limit", "limit must be between 1 and 50"))
+		return
+	}
+
+	recommendations, err := p.service.GetRecommendation().RecommendStudents(c.Request.Context(), projectUUID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+func (p *Project) GetFiles(c *gin.Context) {
+	projectUUID := c.Param("uuid")
+
+	files, err := p.service.GetFile().GetProjectFiles(c.Request.Context(), projectUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+func (p *Project) AttachFile(c *gin.Context) {
+	projectUUID := c.Param("uuid")
+
+	var req dto.AttachFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	err := p.service.GetFile().AttachToProject(c.Request.Context(), projectUUID, req.FileUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File attached to project successfully"})
+}
+
+func (p *Project) DetachFile(c *gin.Context) {
+	projectUUID := c.Param("uuid")
+	fileUUID := c.Param("fileUuid")
+
+	err := p.service.GetFile().DetachFromProject(c.Request.Context(), projectUUID, fileUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File detached from project successfully"})
 }
@@ -3,6 +3,7 @@ package delivery
 import (
 	"github.com/HPNV/growlink-backend/delivery/business"
 	"github.com/HPNV/growlink-backend/delivery/file"
+	"github.com/HPNV/growlink-backend/delivery/health"
 	"github.com/HPNV/growlink-backend/delivery/project"
 	"github.com/HPNV/growlink-backend/delivery/skill"
 	"github.com/HPNV/growlink-backend/delivery/student"
@@ -16,6 +17,7 @@ type IDelivery interface {
 	GetSkill() skill.ISkill
 	GetProject() project.IProject
 	GetFile() file.IFile
+	GetHealth() health.IHealth
 }
 
 type Delivery struct {
@@ -25,6 +27,7 @@ type Delivery struct {
 	skill    skill.ISkill
 	project  project.IProject
 	file     file.IFile
+	health   health.IHealth
 }
 
 func NewDelivery(
@@ -34,6 +37,7 @@ func NewDelivery(
 	skill skill.ISkill,
 	project project.IProject,
 	file file.IFile,
+	health health.IHealth,
 ) IDelivery {
 	return &Delivery{
 		user:     user,
@@ -42,6 +46,7 @@ func NewDelivery(
 		skill:    skill,
 		project:  project,
 		file:     file,
+		health:   health,
 	}
 }
 
@@ -68,3 +73,7 @@ func (d *Delivery) GetProject() project.IProject {
 func (d *Delivery) GetFile() file.IFile {
 	return d.file
 }
+
+func (d *Delivery) GetHealth() health.IHealth {
+	return d.health
+}
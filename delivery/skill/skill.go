@@ -2,7 +2,10 @@ package skill
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
@@ -14,6 +17,12 @@ type ISkill interface {
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
 	GetAll(c *gin.Context)
+	AddAlias(c *gin.Context)
+	RemoveAlias(c *gin.Context)
+	Merge(c *gin.Context)
+	CreateCategory(c *gin.Context)
+	GetCategories(c *gin.Context)
+	Suggest(c *gin.Context)
 }
 
 type Skill struct {
@@ -29,13 +38,13 @@ func NewSkill(service service.IRegistry) ISkill {
 func (s *Skill) Create(c *gin.Context) {
 	var req dto.SkillRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	skill, err := s.service.GetSkill().Create(&req)
+	skill, err := s.service.GetSkill().Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -45,9 +54,9 @@ func (s *Skill) Create(c *gin.Context) {
 func (s *Skill) GetByUUID(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	skill, err := s.service.GetSkill().GetByUUID(uuid)
+	skill, err := s.service.GetSkill().GetByUUID(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		c.Error(err)
 		return
 	}
 
@@ -59,13 +68,13 @@ func (s *Skill) Update(c *gin.Context) {
 	var req dto.SkillRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	skill, err := s.service.GetSkill().Update(uuid, &req)
+	skill, err := s.service.GetSkill().Update(c.Request.Context(), uuid, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -75,9 +84,9 @@ func (s *Skill) Update(c *gin.Context) {
 func (s *Skill) Delete(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	err := s.service.GetSkill().Delete(uuid)
+	err := s.service.GetSkill().Delete(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -85,11 +94,111 @@ func (s *Skill) Delete(c *gin.Context) {
 }
 
 func (s *Skill) GetAll(c *gin.Context) {
-	skills, err := s.service.GetSkill().GetAll()
+	skills, err := s.service.GetSkill().GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, skills)
 }
+
+func (s *Skill) AddAlias(c *gin.Context) {
+	uuid := c.Param("uuid")
+
+	var req dto.SkillAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	skill, err := s.service.GetSkill().AddAlias(c.Request.Context(), uuid, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+func (s *Skill) RemoveAlias(c *gin.Context) {
+	uuid := c.Param("uuid")
+	alias := c.Param("alias")
+
+	err := s.service.GetSkill().RemoveAlias(c.Request.Context(), uuid, alias)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alias removed successfully"})
+}
+
+func (s *Skill) Merge(c *gin.Context) {
+	uuid := c.Param("uuid")
+
+	var req dto.SkillMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	skill, err := s.service.GetSkill().Merge(c.Request.Context(), uuid, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+func (s *Skill) CreateCategory(c *gin.Context) {
+	var req dto.SkillCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	category, err := s.service.GetSkill().CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (s *Skill) GetCategories(c *gin.Context) {
+	categories, err := s.service.GetSkill().GetCategories(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (s *Skill) Suggest(c *gin.Context) {
+	term := strings.TrimSpace(c.Query("q"))
+	if term == "" {
+		c.Error(constant.Validation("query_required", "q is required"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 25 {
+		c.Error(constant.Validation("invalid_limit", "limit must be between 1 and 25"))
+		return
+	}
+
+	suggestions, err := s.service.GetSkill().Suggest(c.Request.Context(), term, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Called on every keystroke, so let clients and proxies reuse answers
+	// briefly
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, suggestions)
+}
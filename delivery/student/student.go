@@ -1,8 +1,11 @@
 package student
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
@@ -18,6 +21,13 @@ type IStudent interface {
 	AddSkill(c *gin.Context)
 	RemoveSkill(c *gin.Context)
 	GetSkills(c *gin.Context)
+	GetApplications(c *gin.Context)
+	GetRecommendedProjects(c *gin.Context)
+	Endorse(c *gin.Context)
+	GetEndorsements(c *gin.Context)
+	GetFiles(c *gin.Context)
+	AttachFile(c *gin.Context)
+	DetachFile(c *gin.Context)
 }
 
 type Student struct {
@@ -33,32 +43,39 @@ func NewStudent(service service.IRegistry) IStudent {
 func (s *Student) Create(c *gin.Context) {
 	var req dto.StudentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
 	// Get user UUID from context (would be set by auth middleware)
-	userUUID, exists := c.Get("user_uuid")
+	userUUID, exists := c.Get(constant.ContextUserUUID)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(constant.ErrUnauthenticated)
 		return
 	}
 
-	student, err := s.service.GetStudent().Create(userUUID.(string), &req)
+	student, err := s.service.GetStudent().Create(c.Request.Context(), userUUID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	// The caller's access token predates the student; without new tokens
+	// the client has to refresh before it can manage it
+	student.Tokens, err = s.service.GetUser().IssueTokens(c.Request.Context(), userUUID.(string))
+	if err != nil {
+		log.Printf("Failed to issue tokens for new student %s: %v", student.UUID, err)
+	}
+
 	c.JSON(http.StatusCreated, student)
 }
 
 func (s *Student) GetByUUID(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	student, err := s.service.GetStudent().GetByUUID(uuid)
+	student, err := s.service.GetStudent().GetByUUID(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		c.Error(err)
 		return
 	}
 
@@ -68,9 +85,9 @@ func (s *Student) GetByUUID(c *gin.Context) {
 func (s *Student) GetByUserUUID(c *gin.Context) {
 	userUUID := c.Param("userUuid")
 
-	student, err := s.service.GetStudent().GetByUserUUID(userUUID)
+	student, err := s.service.GetStudent().GetByUserUUID(c.Request.Context(), userUUID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		c.Error(err)
 		return
 	}
 
@@ -82,13 +99,13 @@ func (s *Student) Update(c *gin.Context) {
 	var req dto.StudentRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	student, err := s.service.GetStudent().Update(uuid, &req)
+	student, err := s.service.GetStudent().Update(c.Request.Context(), uuid, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -98,9 +115,9 @@ func (s *Student) Update(c *gin.Context) {
 func (s *Student) Delete(c *gin.Context) {
 	uuid := c.Param("uuid")
 
-	err := s.service.GetStudent().Delete(uuid)
+	err := s.service.GetStudent().Delete(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -108,9 +125,9 @@ func (s *Student) Delete(c *gin.Context) {
 }
 
 func (s *Student) GetAll(c *gin.Context) {
-	students, err := s.service.GetStudent().GetAll()
+	students, err := s.service.GetStudent().GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -120,18 +137,15 @@ func (s *Student) GetAll(c *gin.Context) {
 func (s *Student) AddSkill(c *gin.Context) {
 	studentUUID := c.Param("uuid")
 
-	var req struct {
-		SkillName string `json:"skill_name" binding:"required"`
-	}
-
+	var req dto.StudentSkillRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	err := s.service.GetStudent().AddSkill(studentUUID, req.SkillName)
+	err := s.service.GetStudent().AddSkill(c.Request.Context(), studentUUID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -146,13 +160,13 @@ func (s *Student) RemoveSkill(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
 
-	err := s.service.GetStudent().RemoveSkill(studentUUID, req.SkillName)
+	err := s.service.GetStudent().RemoveSkill(c.Request.Context(), studentUUID, req.SkillName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -162,11 +176,121 @@ func (s *Student) RemoveSkill(c *gin.Context) {
 func (s *Student) GetSkills(c *gin.Context) {
 	studentUUID := c.Param("uuid")
 
-	skills, err := s.service.GetStudent().GetSkills(studentUUID)
+	skills, err := s.service.GetStudent().GetSkills(c.Request.Context(), studentUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, skills)
 }
+
+func (s *Student) GetApplications(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	applications, err := s.service.GetProject().GetStudentApplications(c.Request.Context(), studentUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+func (s *Student) GetRecommendedProjects(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.Error(constant.Validation("invalid_limit", "limit must be between 1 and 50"))
+		return
+	}
+
+	recommendations, err := s.service.GetRecommendation().RecommendProjects(c.Request.Context(), studentUUID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+func (s *Student) Endorse(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	// Endorsements are given by businesses, so the caller must act as one
+	businessUUID := c.GetString(constant.ContextBusinessUUID)
+	if businessUUID == "" {
+		c.Error(constant.ErrForbidden)
+		return
+	}
+
+	var req dto.EndorsementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	endorsement, err := s.service.GetStudent().Endorse(c.Request.Context(), studentUUID, businessUUID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, endorsement)
+}
+
+func (s *Student) GetEndorsements(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	endorsements, err := s.service.GetStudent().GetEndorsements(c.Request.Context(), studentUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, endorsements)
+}
+
+func (s *Student) GetFiles(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	files, err := s.service.GetFile().GetStudentFiles(c.Request.Context(), studentUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+func (s *Student) AttachFile(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+
+	var req dto.AttachFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+
+	err := s.service.GetFile().AttachToStudent(c.Request.Context(), studentUUID, req.FileUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File attached to student successfully"})
+}
+
+func (s *Student) DetachFile(c *gin.Context) {
+	studentUUID := c.Param("uuid")
+	fileUUID := c.Param("fileUuid")
+
+	err := s.service.GetFile().DetachFromStudent(c.Request.Context(), studentUUID, fileUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File detached from student successfully"})
+}
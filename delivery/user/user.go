@@ -3,6 +3,7 @@ package user
 import (
 	"net/http"
 
+	"github.com/HPNV/growlink-backend/constant"
 	modelDTO "github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/service"
 	"github.com/gin-gonic/gin"
@@ -10,6 +11,8 @@ import (
 
 type IUser interface {
 	Login(*gin.Context)
+	Refresh(*gin.Context)
+	Logout(*gin.Context)
 	Register(*gin.Context)
 	GetAll(*gin.Context)
 	GetDetail(*gin.Context)
@@ -29,35 +32,62 @@ func NewUser(service service.IRegistry) *User {
 func (u *User) Login(c *gin.Context) {
 	var req modelDTO.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
-	user, err := u.service.GetUser().Login(c, req.Email, req.Password)
+	user, err := u.service.GetUser().Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
+func (u *User) Refresh(c *gin.Context) {
+	var req modelDTO.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+	tokens, err := u.service.GetUser().Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (u *User) Logout(c *gin.Context) {
+	var req modelDTO.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(constant.InvalidRequest(err))
+		return
+	}
+	if err := u.service.GetUser().Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
 func (u *User) Register(c *gin.Context) {
 	var req modelDTO.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(constant.InvalidRequest(err))
 		return
 	}
-	user, err := u.service.GetUser().Register(c, req)
+	user, err := u.service.GetUser().Register(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, user)
 }
 
 func (u *User) GetAll(c *gin.Context) {
-	users, err := u.service.GetUser().GetAll()
+	users, err := u.service.GetUser().GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, users)
@@ -66,13 +96,13 @@ func (u *User) GetAll(c *gin.Context) {
 func (u *User) GetDetail(c *gin.Context) {
 	uuid := c.Param("uuid")
 	if uuid == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "UUID parameter is required"})
+		c.Error(constant.Validation("uuid_required", "UUID parameter is required"))
 		return
 	}
 
-	user, err := u.service.GetUser().GetDetail(c, uuid)
+	user, err := u.service.GetUser().GetDetail(c.Request.Context(), uuid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
@@ -94,6 +124,12 @@ func (u *User) GetStudentList(c *gin.Context) {
 		req.Skill = &skill
 	}
 
+	if minProficiency := c.Query("min_proficiency"); minProficiency != "" {
+		req.MinProficiency = &minProficiency
+	}
+
+	req.Endorsed = c.Query("endorsed") == "true"
+
 	// Parse page and limit with defaults
 	page := 1
 	if pageStr := c.DefaultQuery("page", "1"); pageStr != "" {
@@ -103,17 +139,21 @@ func (u *User) GetStudentList(c *gin.Context) {
 	}
 	req.Page = page
 
-	limit := 10
-	if limitStr := c.DefaultQuery("limit", "10"); limitStr != "" {
-		if limitInt, err := parseIntFromString(limitStr); err == nil {
-			limit = limitInt
-		}
+	limit, err := parseIntFromString(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		c.Error(constant.Validation("invalid_limit", "limit must be a positive number"))
+		return
+	}
+	req.Limit = min(limit, modelDTO.MaxPageLimit)
+
+	// Presence of the cursor parameter, even empty, selects keyset pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		req.Cursor = &cursor
 	}
-	req.Limit = limit
 
-	response, err := u.service.GetUser().GetStudentList(&req)
+	response, err := u.service.GetUser().GetStudentList(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
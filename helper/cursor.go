@@ -0,0 +1,37 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/dto"
+)
+
+var ErrInvalidCursor = constant.Validation("invalid_cursor", "cursor is malformed")
+
+// EncodeCursor produces the opaque next_cursor value handed to clients.
+func EncodeCursor(cursor dto.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor. An empty string is
+// the start of the listing and decodes to nil.
+func DecodeCursor(value string) (*dto.Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor dto.Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Key == "" || cursor.UUID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type AccessClaims struct {
+	Role         string `json:"role"`
+	StudentUUID  string `json:"student_uuid,omitempty"`
+	BusinessUUID string `json:"business_uuid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func GenerateAccessToken(cfg config.AuthConfig, claims AccessClaims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(cfg.AccessTTL)
+
+	claims.Issuer = cfg.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+func ParseAccessToken(cfg config.AuthConfig, token string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(cfg.Secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, constant.ErrInvalidToken
+	}
+
+	if claims.Subject == "" {
+		return nil, constant.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken returns an opaque random token. Only its hash is
+// persisted, so the plain value is handed to the client exactly once.
+func GenerateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("failed to generate refresh token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
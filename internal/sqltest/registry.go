@@ -0,0 +1,42 @@
+package sqltest
+
+import (
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/repository"
+	applicationRepo "github.com/HPNV/growlink-backend/repository/application"
+	businessRepo "github.com/HPNV/growlink-backend/repository/business"
+	endorsementRepo "github.com/HPNV/growlink-backend/repository/endorsement"
+	fileRepo "github.com/HPNV/growlink-backend/repository/file"
+	projectRepo "github.com/HPNV/growlink-backend/repository/project"
+	skillRepo "github.com/HPNV/growlink-backend/repository/skill"
+	studentRepo "github.com/HPNV/growlink-backend/repository/student"
+	tokenRepo "github.com/HPNV/growlink-backend/repository/token"
+	uploadRepo "github.com/HPNV/growlink-backend/repository/upload"
+	userRepo "github.com/HPNV/growlink-backend/repository/user"
+	"github.com/HPNV/growlink-backend/storage"
+)
+
+// Image is the image configuration the registry's file repository uses,
+// matching the configuration defaults.
+var Image = config.ImageConfig{ThumbnailSize: 200, MediumSize: 800, JPEGQuality: 85, MaxConcurrent: 2}
+
+// Registry returns a registry of the real repositories, all running on the
+// recorder's database. Objects go to store, which may be nil for tests that
+// never reach storage.
+func (r *Recorder) Registry(store storage.IStorage) *repository.Registry {
+	return repository.NewRegistry(
+		r.DB,
+		userRepo.NewUser(r.DB),
+		skillRepo.NewSkill(r.DB),
+		businessRepo.NewBusiness(r.DB),
+		studentRepo.NewStudent(r.DB),
+		projectRepo.NewProject(r.DB),
+		fileRepo.NewFile(r.DB, store, time.Hour, Image),
+		tokenRepo.NewToken(r.DB),
+		applicationRepo.NewApplication(r.DB),
+		endorsementRepo.NewEndorsement(r.DB),
+		uploadRepo.NewUpload(r.DB, store),
+	)
+}
@@ -0,0 +1,206 @@
+// Package sqltest provides an in-memory database/sql driver for tests. It
+// runs no SQL: every query is answered by a test supplied function and
+// recorded, so tests can assert how many statements a code path issues.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Rows is the result a Responder returns for one statement. Affected is the
+// row count an Exec reports, and Err, when set, fails the statement.
+type Rows struct {
+	Columns  []string
+	Values   [][]driver.Value
+	Affected int64
+	Err      error
+}
+
+// Call is one statement run through a Recorder.
+type Call struct {
+	Query string
+	Args  []driver.Value
+}
+
+// Responder answers a query. Returning nil yields an empty result.
+type Responder func(query string, args []driver.NamedValue) *Rows
+
+// Recorder is a database handle that records the queries run through it.
+type Recorder struct {
+	DB *sqlx.DB
+
+	mu        sync.Mutex
+	calls     []Call
+	commits   int
+	rollbacks int
+	respond   Responder
+}
+
+// Open returns a Recorder whose queries are answered by respond. The handle
+// uses the postgres bind type, like the real connection.
+func Open(respond Responder) *Recorder {
+	r := &Recorder{respond: respond}
+	r.DB = sqlx.NewDb(sql.OpenDB(connector{r}), "postgres")
+	return r
+}
+
+// Count returns how many statements have run since the last Reset.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+// Queries returns the statements run since the last Reset.
+func (r *Recorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queries := make([]string, len(r.calls))
+	for i, call := range r.calls {
+		queries[i] = call.Query
+	}
+	return queries
+}
+
+// Calls returns the statements run since the last Reset with their
+// arguments.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Commits and Rollbacks count the transactions ended since the last Reset.
+func (r *Recorder) Commits() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commits
+}
+
+func (r *Recorder) Rollbacks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rollbacks
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+	r.commits, r.rollbacks = 0, 0
+}
+
+func (r *Recorder) query(query string, args []driver.NamedValue) *Rows {
+	call := Call{Query: query}
+	for _, arg := range args {
+		call.Args = append(call.Args, arg.Value)
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+
+	if rows := r.respond(query, args); rows != nil {
+		return rows
+	}
+	return &Rows{}
+}
+
+type connector struct {
+	r *Recorder
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn{c.r}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return drv{}
+}
+
+type drv struct{}
+
+func (drv) Open(string) (driver.Conn, error) {
+	return nil, errors.New("sqltest: open through sqltest.Open")
+}
+
+type conn struct {
+	r *Recorder
+}
+
+func (c conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.r.query(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &rows{Rows: result}, nil
+}
+
+func (c conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.r.query(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.Affected), nil
+}
+
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("sqltest: prepared statements are not supported")
+}
+
+// Begin starts a transaction that only counts how it ends; statements run
+// in it are recorded like any other.
+func (c conn) Begin() (driver.Tx, error) {
+	return tx{c.r}, nil
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+type tx struct {
+	r *Recorder
+}
+
+func (t tx) Commit() error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.r.commits++
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.r.rollbacks++
+	return nil
+}
+
+type rows struct {
+	*Rows
+	next int
+}
+
+func (r *rows) Columns() []string {
+	return r.Rows.Columns
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.Values) {
+		return io.EOF
+	}
+	copy(dest, r.Values[r.next])
+	r.next++
+	return nil
+}
+
+func (r *rows) Close() error {
+	return nil
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/HPNV/growlink-backend/config"
 	"github.com/HPNV/growlink-backend/delivery"
@@ -10,21 +16,29 @@ import (
 	"github.com/HPNV/growlink-backend/repository"
 	"github.com/HPNV/growlink-backend/routing"
 	"github.com/HPNV/growlink-backend/service"
+	"github.com/HPNV/growlink-backend/storage"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
 	//repository imports
+	applicationRepo "github.com/HPNV/growlink-backend/repository/application"
 	businessRepo "github.com/HPNV/growlink-backend/repository/business"
+	endorsementRepo "github.com/HPNV/growlink-backend/repository/endorsement"
 	fileRepo "github.com/HPNV/growlink-backend/repository/file"
 	projectRepo "github.com/HPNV/growlink-backend/repository/project"
 	skillRepo "github.com/HPNV/growlink-backend/repository/skill"
 	studentRepo "github.com/HPNV/growlink-backend/repository/student"
+	tokenRepo "github.com/HPNV/growlink-backend/repository/token"
+	uploadRepo "github.com/HPNV/growlink-backend/repository/upload"
 	userRepo "github.com/HPNV/growlink-backend/repository/user"
 
 	//service imports
 	businessService "github.com/HPNV/growlink-backend/service/business"
 	fileService "github.com/HPNV/growlink-backend/service/file"
+	healthService "github.com/HPNV/growlink-backend/service/health"
+	policyService "github.com/HPNV/growlink-backend/service/policy"
 	projectService "github.com/HPNV/growlink-backend/service/project"
+	recommendationService "github.com/HPNV/growlink-backend/service/recommendation"
 	skillService "github.com/HPNV/growlink-backend/service/skill"
 	studentService "github.com/HPNV/growlink-backend/service/student"
 	userService "github.com/HPNV/growlink-backend/service/user"
@@ -32,6 +46,7 @@ import (
 	//delivery imports
 	businessDelivery "github.com/HPNV/growlink-backend/delivery/business"
 	fileDelivery "github.com/HPNV/growlink-backend/delivery/file"
+	healthDelivery "github.com/HPNV/growlink-backend/delivery/health"
 	projectDelivery "github.com/HPNV/growlink-backend/delivery/project"
 	skillDelivery "github.com/HPNV/growlink-backend/delivery/skill"
 	studentDelivery "github.com/HPNV/growlink-backend/delivery/student"
@@ -39,59 +54,118 @@ import (
 )
 
 func main() {
-	config.Init()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	if err := config.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := config.CFG.ValidateServer(); err != nil {
+		log.Fatal(err)
+	}
 
 	db, err := connect(config.CFG.DB)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	defer db.Close()
+
+	migrations, err := migration.Source(config.CFG.DB.MigrationPath)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
 
 	if config.CFG.DB.Migration {
-		if err := migration.AutoMigrate(db, "./migration"); err != nil {
+		if err := migration.AutoMigrate(db, migrations); err != nil {
 			log.Fatal("Failed to run migrations:", err)
 		}
 		log.Println("Database migrations completed successfully")
+	} else if err := migration.Verify(db, migrations); err != nil {
+		log.Fatal("Failed to verify migrations:", err)
+	}
+
+	store, err := storage.New(config.CFG.Storage)
+	if err != nil {
+		log.Fatal("Failed to set up storage:", err)
 	}
 
-	repo := initRepository(db)
-	service := initService(repo)
+	repo := initRepository(db, store)
+	service := initService(repo, migrations, store)
 	delivery := initDelivery(service)
 
 	fmt.Println("Starting server on port:", config.CFG.Server)
 
-	routing.NewRoute(config.CFG.Server, delivery).SetupRoutes()
+	route := routing.NewRoute(config.CFG.Server, config.CFG.Auth, config.CFG.Storage, service.GetPolicy(), delivery)
+	route.SetupRoutes()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go cleanUploads(ctx, service.GetFile())
+
+	if err := route.Run(ctx); err != nil {
+		log.Fatal("Server error:", err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// cleanUploads removes expired resumable uploads every hour until ctx is
+// done.
+func cleanUploads(ctx context.Context, file fileService.IFile) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		removed, err := file.CleanupUploads(ctx)
+		if err != nil {
+			log.Println("Failed to clean up uploads:", err)
+		} else if removed > 0 {
+			log.Println("Removed expired uploads:", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func connect(
-	config config.DatabaseConfig,
+	cfg config.DatabaseConfig,
 ) (*sqlx.DB, error) {
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		config.Host, config.Port, config.User, config.Password, config.Name)
+	dsn := cfg.ConnString()
 
-	fmt.Println("Connecting to database:", psqlInfo)
+	fmt.Println("Connecting to database:", config.RedactDSN(dsn))
 
-	db, err := sqlx.Connect("postgres", psqlInfo)
+	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, err
 	}
 
-	err = db.Ping()
-	if err != nil {
-		log.Fatal("Failed to ping database:", err)
-	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	fmt.Println("Successfully connected to database!")
 
 	return db, nil
 }
 
-func initRepository(db *sqlx.DB) *repository.Registry {
+func initRepository(db *sqlx.DB, store storage.IStorage) *repository.Registry {
 	user := userRepo.NewUser(db)
 	skill := skillRepo.NewSkill(db)
 	business := businessRepo.NewBusiness(db)
 	student := studentRepo.NewStudent(db)
 	project := projectRepo.NewProject(db)
-	file := fileRepo.NewFile(db)
+	file := fileRepo.NewFile(db, store, config.CFG.Storage.URLExpiry, config.CFG.Image)
+	token := tokenRepo.NewToken(db)
+	application := applicationRepo.NewApplication(db)
+	endorsement := endorsementRepo.NewEndorsement(db)
+	upload := uploadRepo.NewUpload(db, store)
 
 	repo := repository.NewRegistry(
 		db,
@@ -101,18 +175,25 @@ func initRepository(db *sqlx.DB) *repository.Registry {
 		student,
 		project,
 		file,
+		token,
+		application,
+		endorsement,
+		upload,
 	)
 
 	return repo
 }
 
-func initService(repo repository.IRegistry) *service.Registry {
-	user := userService.NewUser(repo)
+func initService(repo repository.IRegistry, migrations fs.FS, store storage.IStorage) *service.Registry {
+	user := userService.NewUser(repo, config.CFG.Auth)
 	skill := skillService.NewSkill(repo)
 	business := businessService.NewBusiness(repo)
 	student := studentService.NewStudent(repo)
 	project := projectService.NewProject(repo)
 	file := fileService.NewFile(repo)
+	policy := policyService.NewPolicy(repo)
+	recommendation := recommendationService.NewRecommendation(repo)
+	health := healthService.NewHealth(repo, migrations, store)
 
 	serviceRegistry := service.NewRegistry(
 		user,
@@ -121,6 +202,9 @@ func initService(repo repository.IRegistry) *service.Registry {
 		student,
 		project,
 		file,
+		policy,
+		recommendation,
+		health,
 	)
 
 	return serviceRegistry
@@ -133,6 +217,7 @@ func initDelivery(service service.IRegistry) delivery.IDelivery {
 	skill := skillDelivery.NewSkill(service)
 	project := projectDelivery.NewProject(service)
 	file := fileDelivery.NewFile(service)
+	health := healthDelivery.NewHealth(service)
 
 	delivery := delivery.NewDelivery(
 		user,
@@ -141,6 +226,7 @@ func initDelivery(service service.IRegistry) delivery.IDelivery {
 		skill,
 		project,
 		file,
+		health,
 	)
 
 	return delivery
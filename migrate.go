@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/migration"
+)
+
+// migrationDir is where "migrate create" writes new files. They are
+// embedded into the binary on the next build.
+const migrationDir = "./migration"
+
+const migrateUsage = `usage: growlink migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [n]       roll back the last n migrations (default 1)
+  status         list applied and pending migrations
+  create <name>  scaffold a new numbered up/down migration pair`
+
+// runMigrate handles "growlink migrate ..." without starting the HTTP
+// server and returns the process exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		upPath, downPath, err := migration.Create(migrationDir, args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "create failed:", err)
+			return 1
+		}
+
+		fmt.Println("Created", upPath)
+		fmt.Println("Created", downPath)
+		return 0
+	}
+
+	if err := config.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db, err := connect(config.CFG.DB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to database:", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrations, err := migration.Source(config.CFG.DB.MigrationPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	migrator := migration.NewMigrator(db, migrations)
+
+	switch args[0] {
+	case "up":
+		err = migrator.RunMigrations()
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, "down expects a positive number of migrations")
+				return 2
+			}
+		}
+		err = migrator.Rollback(n)
+	case "status":
+		err = printMigrationStatus(migrator)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %v\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+func printMigrationStatus(migrator *migration.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tSTATUS\tAPPLIED AT")
+
+	pending := 0
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "applied (file missing)"
+		case status.Modified:
+			state = "applied (modified)"
+		case status.Applied:
+			state = "applied"
+		default:
+			pending++
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Filename, state, status.AppliedAt)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d migrations, %d pending\n", len(statuses), pending)
+	return nil
+}
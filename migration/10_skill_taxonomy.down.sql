@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_skills_name_lower;
+
+DROP TABLE IF EXISTS skill_aliases;
+
+ALTER TABLE skills DROP COLUMN IF EXISTS category_uuid;
+
+DROP TABLE IF EXISTS skill_categories;
@@ -0,0 +1,77 @@
+CREATE TABLE IF NOT EXISTS skill_categories (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_uuid UUID REFERENCES skill_categories(uuid) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_skill_categories_name ON skill_categories (lower(name));
+
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS category_uuid UUID REFERENCES skill_categories(uuid) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS skill_aliases (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    skill_uuid UUID NOT NULL REFERENCES skills(uuid) ON DELETE CASCADE,
+    alias VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_skill_aliases_alias ON skill_aliases (lower(alias));
+
+CREATE INDEX IF NOT EXISTS idx_skill_aliases_skill_uuid ON skill_aliases(skill_uuid);
+
+-- Collapse whitespace so that "go " and "go" compare equal below.
+UPDATE skills SET name = btrim(regexp_replace(name, '\s+', ' ', 'g'));
+
+-- Merge skills whose names differ only by case into the oldest one before
+-- the unique index goes on, the way the skill merge endpoint does: a student
+-- who has both keeps the higher proficiency and experience, and the merged
+-- name becomes an alias.
+DO $$
+DECLARE
+    dup RECORD;
+BEGIN
+    FOR dup IN
+        SELECT s.uuid AS source, s.name AS source_name, c.uuid AS target
+        FROM skills s
+        JOIN LATERAL (
+            SELECT k.uuid FROM skills k
+            WHERE lower(k.name) = lower(s.name)
+            ORDER BY k.created_at, k.uuid
+            LIMIT 1
+        ) c ON c.uuid <> s.uuid
+    LOOP
+        INSERT INTO student_skills (student_uuid, skill_uuid, proficiency, years_experience)
+        SELECT student_uuid, dup.target, proficiency, years_experience
+        FROM student_skills WHERE skill_uuid = dup.source
+        ON CONFLICT (student_uuid, skill_uuid) DO UPDATE
+        SET proficiency = CASE
+                WHEN array_position(ARRAY['beginner', 'intermediate', 'advanced', 'expert']::varchar[], EXCLUDED.proficiency)
+                    > array_position(ARRAY['beginner', 'intermediate', 'advanced', 'expert']::varchar[], student_skills.proficiency)
+                THEN EXCLUDED.proficiency
+                ELSE student_skills.proficiency
+            END,
+            years_experience = GREATEST(student_skills.years_experience, EXCLUDED.years_experience);
+
+        DELETE FROM skill_endorsements se
+        WHERE se.skill_uuid = dup.source AND EXISTS (
+            SELECT 1 FROM skill_endorsements t
+            WHERE t.skill_uuid = dup.target AND t.student_uuid = se.student_uuid AND t.project_uuid = se.project_uuid
+        );
+
+        UPDATE skill_endorsements SET skill_uuid = dup.target WHERE skill_uuid = dup.source;
+
+        INSERT INTO project_skills (project_uuid, skill_uuid)
+        SELECT project_uuid, dup.target FROM project_skills WHERE skill_uuid = dup.source
+        ON CONFLICT (project_uuid, skill_uuid) DO NOTHING;
+
+        INSERT INTO skill_aliases (skill_uuid, alias)
+        VALUES (dup.target, dup.source_name)
+        ON CONFLICT DO NOTHING;
+
+        DELETE FROM skills WHERE uuid = dup.source;
+    END LOOP;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_skills_name_lower ON skills (lower(name));
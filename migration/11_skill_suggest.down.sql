@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_project_skills_skill_uuid;
+
+DROP INDEX IF EXISTS idx_student_skills_skill_uuid;
+
+DROP INDEX IF EXISTS idx_skill_aliases_alias_prefix;
+
+DROP INDEX IF EXISTS idx_skill_aliases_alias_trgm;
+
+DROP INDEX IF EXISTS idx_skills_name_prefix;
+
+DROP INDEX IF EXISTS idx_skills_name_trgm;
@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_skills_name_trgm ON skills USING GIN (lower(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_skills_name_prefix ON skills (lower(name) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_skill_aliases_alias_trgm ON skill_aliases USING GIN (lower(alias) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_skill_aliases_alias_prefix ON skill_aliases (lower(alias) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_student_skills_skill_uuid ON student_skills(skill_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_project_skills_skill_uuid ON project_skills(skill_uuid);
@@ -0,0 +1,3 @@
+UPDATE files
+SET file_path = 'static/' || file_path
+WHERE file_path !~ '^(\./)?static/';
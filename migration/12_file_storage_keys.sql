@@ -0,0 +1,5 @@
+-- file_path now holds the storage key ("images/<name>") rather than a path
+-- relative to the working directory ("static/images/<name>").
+UPDATE files
+SET file_path = regexp_replace(file_path, '^(\./)?static/', '')
+WHERE file_path ~ '^(\./)?static/';
@@ -0,0 +1,10 @@
+DELETE FROM files WHERE parent_uuid IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_files_parent_variant;
+
+ALTER TABLE files
+DROP CONSTRAINT IF EXISTS files_variant_has_parent,
+DROP COLUMN IF EXISTS parent_uuid,
+DROP COLUMN IF EXISTS variant,
+DROP COLUMN IF EXISTS width,
+DROP COLUMN IF EXISTS height;
@@ -0,0 +1,12 @@
+-- Resized copies of an uploaded image are stored as child rows of the
+-- upload, one per variant name.
+ALTER TABLE files
+ADD COLUMN IF NOT EXISTS parent_uuid UUID REFERENCES files(uuid) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS variant VARCHAR(20),
+ADD COLUMN IF NOT EXISTS width INT,
+ADD COLUMN IF NOT EXISTS height INT;
+
+ALTER TABLE files
+ADD CONSTRAINT files_variant_has_parent CHECK ((parent_uuid IS NULL) = (variant IS NULL));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_parent_variant ON files(parent_uuid, variant) WHERE parent_uuid IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS project_files;
+
+DROP TABLE IF EXISTS student_files;
+
+ALTER TABLE files
+DROP COLUMN IF EXISTS purpose;
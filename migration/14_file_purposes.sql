@@ -0,0 +1,21 @@
+ALTER TABLE files
+ADD COLUMN IF NOT EXISTS purpose VARCHAR(30) NOT NULL DEFAULT 'image'
+CHECK (purpose IN ('image', 'resume', 'portfolio', 'project_brief'));
+
+CREATE TABLE IF NOT EXISTS student_files (
+    student_uuid UUID NOT NULL REFERENCES students(uuid) ON DELETE CASCADE,
+    file_uuid UUID NOT NULL REFERENCES files(uuid) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (student_uuid, file_uuid)
+);
+
+CREATE TABLE IF NOT EXISTS project_files (
+    project_uuid UUID NOT NULL REFERENCES projects(uuid) ON DELETE CASCADE,
+    file_uuid UUID NOT NULL REFERENCES files(uuid) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_uuid, file_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_files_file_uuid ON student_files(file_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_project_files_file_uuid ON project_files(file_uuid);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS file_uploads (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    uploaded_by UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL
+    CHECK (purpose IN ('image', 'resume', 'portfolio', 'project_brief')),
+    file_name VARCHAR(255) NOT NULL,
+    upload_length BIGINT NOT NULL CHECK (upload_length > 0),
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    chunk_keys TEXT[] NOT NULL DEFAULT '{}',
+    file_uuid UUID REFERENCES files(uuid) ON DELETE SET NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (upload_offset BETWEEN 0 AND upload_length)
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_uploads_uploaded_by ON file_uploads(uploaded_by);
@@ -0,0 +1,12 @@
+UPDATE project_applications SET status = 'rejected' WHERE status = 'removed';
+
+DROP INDEX IF EXISTS idx_project_applications_active;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_applications_active
+ON project_applications(project_uuid, student_uuid)
+WHERE status NOT IN ('rejected', 'withdrawn');
+
+ALTER TABLE project_applications DROP CONSTRAINT IF EXISTS project_applications_status_check;
+
+ALTER TABLE project_applications
+ADD CONSTRAINT project_applications_status_check CHECK (status IN ('pending', 'shortlisted', 'accepted', 'rejected', 'withdrawn'));
@@ -0,0 +1,18 @@
+ALTER TABLE project_applications DROP CONSTRAINT IF EXISTS project_applications_status_check;
+
+ALTER TABLE project_applications
+ADD CONSTRAINT project_applications_status_check CHECK (status IN ('pending', 'shortlisted', 'accepted', 'rejected', 'withdrawn', 'removed'));
+
+DROP INDEX IF EXISTS idx_project_applications_active;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_applications_active
+ON project_applications(project_uuid, student_uuid)
+WHERE status NOT IN ('rejected', 'withdrawn', 'removed');
+
+UPDATE project_applications pa
+SET status = 'removed', updated_at = CURRENT_TIMESTAMP
+WHERE pa.status = 'accepted'
+AND NOT EXISTS (
+    SELECT 1 FROM student_projects sp
+    WHERE sp.project_uuid = pa.project_uuid AND sp.student_uuid = pa.student_uuid
+);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS student_projects;
+
+DROP TABLE IF EXISTS project_skills;
+
+DROP TABLE IF EXISTS student_skills;
+
+DROP TABLE IF EXISTS projects;
+
+DROP TABLE IF EXISTS skills;
+
+DROP TABLE IF EXISTS students;
+
+DROP TABLE IF EXISTS businesses;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS projects
+DROP COLUMN IF EXISTS duration,
+DROP COLUMN IF EXISTS timeline,
+DROP COLUMN IF EXISTS deliverables;
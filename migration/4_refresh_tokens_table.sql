@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by UUID REFERENCES refresh_tokens(uuid) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_uuid ON refresh_tokens(user_uuid);
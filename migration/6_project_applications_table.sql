@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS project_applications (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    project_uuid UUID NOT NULL REFERENCES projects(uuid) ON DELETE CASCADE,
+    student_uuid UUID NOT NULL REFERENCES students(uuid) ON DELETE CASCADE,
+    status VARCHAR(20) CHECK (status IN ('pending', 'shortlisted', 'accepted', 'rejected', 'withdrawn')) NOT NULL DEFAULT 'pending',
+    cover_letter TEXT NOT NULL DEFAULT '',
+    attachment_uuids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_applications_active
+ON project_applications(project_uuid, student_uuid)
+WHERE status NOT IN ('rejected', 'withdrawn');
+
+CREATE INDEX IF NOT EXISTS idx_project_applications_student_uuid ON project_applications(student_uuid);
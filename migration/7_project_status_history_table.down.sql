@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS project_status_history;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+
+-- The old lifecycle has no draft or closed states: drafts become open and
+-- cancelled or archived projects count as completed.
+UPDATE projects SET status = CASE status
+    WHEN 'draft' THEN 'open'
+    ELSE 'completed'
+END
+WHERE status IN ('draft', 'cancelled', 'archived');
+
+ALTER TABLE projects
+ADD CONSTRAINT projects_status_check CHECK (status IN ('open', 'in_progress', 'completed'));
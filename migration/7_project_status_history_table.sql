@@ -0,0 +1,15 @@
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+
+ALTER TABLE projects
+ADD CONSTRAINT projects_status_check CHECK (status IN ('draft', 'open', 'in_progress', 'completed', 'cancelled', 'archived'));
+
+CREATE TABLE IF NOT EXISTS project_status_history (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    project_uuid UUID NOT NULL REFERENCES projects(uuid) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by UUID REFERENCES users(uuid) ON DELETE SET NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_status_history_project_uuid ON project_status_history(project_uuid);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS projects_search_vector_trigger ON projects;
+
+DROP FUNCTION IF EXISTS projects_search_vector_update();
+
+ALTER TABLE projects DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS skill_endorsements;
+
+ALTER TABLE student_skills
+DROP COLUMN IF EXISTS proficiency,
+DROP COLUMN IF EXISTS years_experience;
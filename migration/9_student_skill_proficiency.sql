@@ -0,0 +1,21 @@
+ALTER TABLE student_skills
+ADD COLUMN IF NOT EXISTS proficiency VARCHAR(20) NOT NULL DEFAULT 'beginner'
+CHECK (proficiency IN ('beginner', 'intermediate', 'advanced', 'expert'));
+
+ALTER TABLE student_skills
+ADD COLUMN IF NOT EXISTS years_experience INT NOT NULL DEFAULT 0
+CHECK (years_experience >= 0);
+
+CREATE TABLE IF NOT EXISTS skill_endorsements (
+    uuid UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    student_uuid UUID NOT NULL,
+    skill_uuid UUID NOT NULL,
+    business_uuid UUID NOT NULL REFERENCES businesses(uuid) ON DELETE CASCADE,
+    project_uuid UUID NOT NULL REFERENCES projects(uuid) ON DELETE CASCADE,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (student_uuid, skill_uuid) REFERENCES student_skills(student_uuid, skill_uuid) ON DELETE CASCADE,
+    UNIQUE (student_uuid, skill_uuid, project_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skill_endorsements_student_uuid ON skill_endorsements(student_uuid);
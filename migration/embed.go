@@ -0,0 +1,8 @@
+package migration
+
+import "embed"
+
+// Files holds the migrations compiled into the binary.
+//
+//go:embed *.sql
+var Files embed.FS
@@ -1,9 +1,12 @@
 package migration
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,25 +17,59 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// lockKey identifies the Postgres advisory lock held while migrating, so
+// instances starting at the same time apply migrations one after another.
+const lockKey int64 = 0x67726f776c696e6b
+
+// downSuffix marks the file that reverts the migration of the same name.
+const downSuffix = ".down.sql"
+
 type MigrationRecord struct {
-	ID        int    `db:"id"`
-	Filename  string `db:"filename"`
-	AppliedAt string `db:"applied_at"`
+	ID        int     `db:"id"`
+	Filename  string  `db:"filename"`
+	Checksum  *string `db:"checksum"`
+	AppliedAt string  `db:"applied_at"`
 }
 
+// Migrator applies the .sql files at the root of FS, usually the embedded
+// Files or a development directory opened with Source.
 type Migrator struct {
-	DB            *sqlx.DB
-	MigrationPath string
+	DB *sqlx.DB
+	FS fs.FS
 }
 
-func NewMigrator(db *sqlx.DB, migrationPath string) *Migrator {
+func NewMigrator(db *sqlx.DB, fsys fs.FS) *Migrator {
 	return &Migrator{
-		DB:            db,
-		MigrationPath: migrationPath,
+		DB: db,
+		FS: fsys,
+	}
+}
+
+// withLock runs fn on a dedicated connection that holds the migration
+// advisory lock. The lock is session scoped, so everything fn does must go
+// through conn.
+func (m *Migrator) withLock(fn func(ctx context.Context, conn *sqlx.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.DB.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if err := m.createMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(ctx, conn)
 }
 
-func (m *Migrator) createMigrationsTable() error {
+func (m *Migrator) createMigrationsTable(ctx context.Context, conn *sqlx.Conn) error {
 	query := `
 		CREATE TABLE IF NOT EXISTS migrations (
 			id SERIAL PRIMARY KEY,
@@ -40,49 +77,44 @@ func (m *Migrator) createMigrationsTable() error {
 			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`
 
-	_, err := m.DB.Exec(query)
+	_, err := conn.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
+	_, err = conn.ExecContext(ctx, "ALTER TABLE migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)")
+	if err != nil {
+		return fmt.Errorf("failed to add checksum column: %w", err)
+	}
+
 	return nil
 }
 
-func (m *Migrator) getAppliedMigrations() (map[string]bool, error) {
-	applied := make(map[string]bool)
-
+func (m *Migrator) getAppliedMigrations(ctx context.Context, conn *sqlx.Conn) ([]MigrationRecord, error) {
 	var records []MigrationRecord
-	err := m.DB.Select(&records, "SELECT filename FROM migrations ORDER BY id")
+	err := conn.SelectContext(ctx, &records, "SELECT id, filename, checksum, applied_at FROM migrations ORDER BY id")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	for _, record := range records {
-		applied[record.Filename] = true
-	}
-
-	return applied, nil
+	return records, nil
 }
 
 func (m *Migrator) getSQLFiles() ([]string, error) {
 	var files []string
 
-	err := filepath.WalkDir(m.MigrationPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(d.Name()), ".sql") {
-			files = append(files, d.Name())
-		}
-
-		return nil
-	})
-
+	entries, err := fs.ReadDir(m.FS, ".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migration files: %w", err)
 	}
 
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if !entry.IsDir() && strings.HasSuffix(name, ".sql") && !strings.HasSuffix(name, downSuffix) {
+			files = append(files, entry.Name())
+		}
+	}
+
 	sort.Slice(files, func(i, j int) bool {
 		numI := extractNumber(files[i])
 		numJ := extractNumber(files[j])
@@ -117,90 +149,481 @@ func extractNumber(filename string) int {
 	return num
 }
 
-func (m *Migrator) executeSQLFile(filename string) error {
-	filePath := filepath.Join(m.MigrationPath, filename)
+// splitStatements splits a migration file on semicolons that terminate a
+// statement, leaving semicolons inside quotes, escape strings, comments and
+// dollar-quoted function bodies untouched.
+func splitStatements(content string) []string {
+	var statements []string
+	start := 0
+
+	for i := 0; i < len(content); {
+		rest := content[i:]
+		skip := 1
+
+		switch {
+		case (rest[0] == 'E' || rest[0] == 'e') && strings.HasPrefix(rest[1:], "'") && (i == 0 || !isIdentByte(content[i-1])):
+			skip = escapeStringLength(rest)
+		case rest[0] == '\'' || rest[0] == '"':
+			skip = tokenLength(rest, 1, rest[:1])
+		case strings.HasPrefix(rest, "--"):
+			skip = tokenLength(rest, 2, "\n")
+		case strings.HasPrefix(rest, "/*"):
+			skip = tokenLength(rest, 2, "*/")
+		case rest[0] == '$' && dollarTag(rest) != "":
+			tag := dollarTag(rest)
+			skip = tokenLength(rest, len(tag), tag)
+		case rest[0] == ';':
+			statements = append(statements, content[start:i])
+			start = i + 1
+		}
+
+		i += skip
+	}
+
+	return append(statements, content[start:])
+}
+
+// tokenLength returns how many bytes of s belong to a token whose opening
+// delimiter is open bytes long and which ends with closer. Unterminated
+// tokens run to the end of s.
+func tokenLength(s string, open int, closer string) int {
+	end := strings.Index(s[open:], closer)
+	if end < 0 {
+		return len(s)
+	}
+	return open + end + len(closer)
+}
+
+// escapeStringLength returns how many bytes of s belong to the E'...' escape
+// string at its start, where a backslash escapes the byte after it.
+// Unterminated strings run to the end of s.
+func escapeStringLength(s string) int {
+	for j := 2; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '\'':
+			if j+1 < len(s) && s[j+1] == '\'' {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// isIdentByte reports whether c can be part of an identifier, so that a
+// name ending in E right before a quote is not read as an escape string.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// dollarTag returns the opening dollar-quote tag ($$ or $name$) at the start
+// of s, or an empty string when s does not start one.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		if c == '$' {
+			return s[:j+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
 
-	content, err := ioutil.ReadFile(filePath)
+// readFile returns the contents of a migration file and its checksum.
+func (m *Migrator) readFile(filename string) (string, string, error) {
+	content, err := fs.ReadFile(m.FS, filename)
 	if err != nil {
-		return fmt.Errorf("failed to read migration file %s: %w", filename, err)
+		return "", "", fmt.Errorf("failed to read migration file %s: %w", filename, err)
 	}
 
-	statements := strings.Split(string(content), ";")
+	sum := sha256.Sum256(content)
+	return string(content), hex.EncodeToString(sum[:]), nil
+}
 
-	for _, statement := range statements {
+// executeSQL runs every statement of a migration file inside tx.
+func executeSQL(ctx context.Context, tx *sqlx.Tx, filename, content string) error {
+	for _, statement := range splitStatements(content) {
 		statement = strings.TrimSpace(statement)
 		if statement == "" {
 			continue
 		}
 
-		_, err := m.DB.Exec(statement)
+		_, err := tx.ExecContext(ctx, statement)
 		if err != nil {
 			return fmt.Errorf("failed to execute statement in %s: %w\nStatement: %s", filename, err, statement)
 		}
 	}
 
-	_, err = m.DB.Exec("INSERT INTO migrations (filename) VALUES ($1)", filename)
+	return nil
+}
+
+// applyFile runs a migration and records it in a single transaction, so a
+// failing file leaves no partial changes behind.
+func (m *Migrator) applyFile(ctx context.Context, conn *sqlx.Conn, filename string) error {
+	content, checksum, err := m.readFile(filename)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for %s: %w", filename, err)
+	}
+	defer tx.Rollback()
+
+	if err := executeSQL(ctx, tx, filename, content); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO migrations (filename, checksum) VALUES ($1, $2)", filename, checksum)
 	if err != nil {
 		return fmt.Errorf("failed to record migration %s: %w", filename, err)
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (m *Migrator) RunMigrations() error {
-	log.Println("Starting database migrations...")
+// revertFile runs the .down.sql pair of an applied migration and forgets it
+// in a single transaction.
+func (m *Migrator) revertFile(ctx context.Context, conn *sqlx.Conn, filename string) error {
+	downFile := strings.TrimSuffix(filename, ".sql") + downSuffix
 
-	if err := m.createMigrationsTable(); err != nil {
+	content, _, err := m.readFile(downFile)
+	if err != nil {
 		return err
 	}
 
-	applied, err := m.getAppliedMigrations()
+	tx, err := conn.BeginTxx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction for %s: %w", downFile, err)
+	}
+	defer tx.Rollback()
+
+	if err := executeSQL(ctx, tx, downFile, content); err != nil {
 		return err
 	}
 
-	files, err := m.getSQLFiles()
+	_, err = tx.ExecContext(ctx, "DELETE FROM migrations WHERE filename = $1", filename)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to unrecord migration %s: %w", filename, err)
+	}
+
+	return tx.Commit()
+}
+
+// verifyChecksums fails when an applied migration was edited afterwards.
+// Rows recorded before checksums existed get the current checksum instead.
+func (m *Migrator) verifyChecksums(ctx context.Context, conn *sqlx.Conn, applied []MigrationRecord) error {
+	for _, record := range applied {
+		if _, err := fs.Stat(m.FS, record.Filename); errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Applied migration %s not found on disk, cannot verify checksum", record.Filename)
+			continue
+		}
+
+		_, checksum, err := m.readFile(record.Filename)
+		if err != nil {
+			return err
+		}
+
+		if record.Checksum == nil {
+			_, err := conn.ExecContext(ctx, "UPDATE migrations SET checksum = $1 WHERE id = $2", checksum, record.ID)
+			if err != nil {
+				return fmt.Errorf("failed to record checksum for %s: %w", record.Filename, err)
+			}
+			continue
+		}
+
+		if *record.Checksum != checksum {
+			return fmt.Errorf("migration %s was modified after it was applied (checksum %s, recorded %s)", record.Filename, checksum, *record.Checksum)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) RunMigrations() error {
+	log.Println("Starting database migrations...")
+
+	return m.withLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		records, err := m.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := m.verifyChecksums(ctx, conn, records); err != nil {
+			return err
+		}
+
+		applied := make(map[string]bool, len(records))
+		for _, record := range records {
+			applied[record.Filename] = true
+		}
+
+		files, err := m.getSQLFiles()
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 {
+			log.Println("No migration files found")
+			return nil
+		}
+
+		migrationsRun := 0
+		for _, filename := range files {
+			if applied[filename] {
+				log.Printf("Migration %s already applied, skipping", filename)
+				continue
+			}
+
+			log.Printf("Running migration: %s", filename)
+			if err := m.applyFile(ctx, conn, filename); err != nil {
+				return fmt.Errorf("migration %s failed: %w", filename, err)
+			}
+
+			log.Printf("Migration %s completed successfully", filename)
+			migrationsRun++
+		}
+
+		if migrationsRun == 0 {
+			log.Println("All migrations are up to date")
+		} else {
+			log.Printf("Successfully applied %d migrations", migrationsRun)
+		}
+
+		return nil
+	})
+}
+
+// Verify checks the applied migrations against their files without running
+// any, so a server started with migrations off still refuses edited ones.
+func (m *Migrator) Verify() error {
+	return m.withLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		records, err := m.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return m.verifyChecksums(ctx, conn, records)
+	})
+}
+
+// Rollback reverts the n most recently applied migrations, newest first,
+// using their .down.sql files.
+func (m *Migrator) Rollback(n int) error {
+	if n < 1 {
+		return fmt.Errorf("rollback count must be positive, got %d", n)
 	}
 
-	if len(files) == 0 {
-		log.Println("No migration files found")
+	return m.withLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		records, err := m.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if n > len(records) {
+			n = len(records)
+		}
+
+		for i := len(records) - 1; i >= len(records)-n; i-- {
+			filename := records[i].Filename
+
+			log.Printf("Rolling back migration: %s", filename)
+			if err := m.revertFile(ctx, conn, filename); err != nil {
+				return fmt.Errorf("rollback of %s failed: %w", filename, err)
+			}
+
+			log.Printf("Migration %s rolled back successfully", filename)
+		}
+
+		log.Printf("Rolled back %d migrations", n)
+
 		return nil
+	})
+}
+
+// MigrationStatus describes one migration as seen on disk and in the
+// migrations table.
+type MigrationStatus struct {
+	Filename  string
+	Applied   bool
+	AppliedAt string
+	// Modified is set when the file changed after it was applied.
+	Modified bool
+	// Missing is set when the migration was applied but its file is gone.
+	Missing bool
+}
+
+// Status lists every known migration in the order it would be applied.
+// Applied migrations whose files are gone are listed first.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		records, err := m.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		files, err := m.getSQLFiles()
+		if err != nil {
+			return err
+		}
+
+		onDisk := make(map[string]bool, len(files))
+		for _, filename := range files {
+			onDisk[filename] = true
+		}
+
+		applied := make(map[string]MigrationRecord, len(records))
+		for _, record := range records {
+			applied[record.Filename] = record
+			if !onDisk[record.Filename] {
+				statuses = append(statuses, MigrationStatus{
+					Filename:  record.Filename,
+					Applied:   true,
+					AppliedAt: record.AppliedAt,
+					Missing:   true,
+				})
+			}
+		}
+
+		for _, filename := range files {
+			status := MigrationStatus{Filename: filename}
+
+			if record, ok := applied[filename]; ok {
+				status.Applied = true
+				status.AppliedAt = record.AppliedAt
+
+				_, checksum, err := m.readFile(filename)
+				if err != nil {
+					return err
+				}
+				status.Modified = record.Checksum != nil && *record.Checksum != checksum
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending lists the migration files that have not been applied yet. Unlike
+// Status it takes no lock and creates nothing, so it is cheap enough to call
+// from a readiness probe.
+func (m *Migrator) Pending(ctx context.Context) ([]string, error) {
+	var applied []string
+	if err := m.DB.SelectContext(ctx, &applied, "SELECT filename FROM migrations"); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	files, err := m.getSQLFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]bool, len(applied))
+	for _, filename := range applied {
+		done[filename] = true
 	}
 
-	migrationsRun := 0
+	var pending []string
 	for _, filename := range files {
-		if applied[filename] {
-			log.Printf("Migration %s already applied, skipping", filename)
-			continue
+		if !done[filename] {
+			pending = append(pending, filename)
 		}
+	}
+
+	return pending, nil
+}
+
+// Create scaffolds an empty up and down migration in dir, numbered after the
+// highest existing file, and returns their paths. Embedded migrations only
+// pick the files up on the next build.
+func Create(dir, name string) (string, string, error) {
+	name = migrationName(name)
+	if name == "" {
+		return "", "", fmt.Errorf("migration name must contain letters or digits")
+	}
 
-		log.Printf("Running migration: %s", filename)
-		if err := m.executeSQLFile(filename); err != nil {
-			return fmt.Errorf("migration %s failed: %w", filename, err)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read migration directory: %w", err)
+	}
+
+	next := 1
+	for _, entry := range entries {
+		if num := extractNumber(entry.Name()); num >= next {
+			next = num + 1
 		}
+	}
+
+	base := fmt.Sprintf("%d_%s", next, name)
+	upPath := filepath.Join(dir, base+".sql")
+	downPath := filepath.Join(dir, base+downSuffix)
 
-		log.Printf("Migration %s completed successfully", filename)
-		migrationsRun++
+	if err := os.WriteFile(upPath, []byte("-- "+base+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", upPath, err)
+	}
+	if err := os.WriteFile(downPath, []byte("-- Reverts "+base+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", downPath, err)
 	}
 
-	if migrationsRun == 0 {
-		log.Println("All migrations are up to date")
-	} else {
-		log.Printf("Successfully applied %d migrations", migrationsRun)
+	return upPath, downPath, nil
+}
+
+// migrationName turns free text into a snake_case file name fragment.
+func migrationName(name string) string {
+	var b strings.Builder
+	underscore := false
+
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			underscore = false
+		case b.Len() > 0 && !underscore:
+			b.WriteByte('_')
+			underscore = true
+		}
 	}
 
-	return nil
+	return strings.TrimSuffix(b.String(), "_")
 }
 
-func AutoMigrate(db *sqlx.DB, migrationPath string) error {
+// Source returns the migrations to run: the files embedded in the binary,
+// or the directory at path when one is given, which lets development pick up
+// edits without rebuilding.
+func Source(path string) (fs.FS, error) {
+	if path == "" {
+		return Files, nil
+	}
 
-	if _, err := os.Stat(migrationPath); os.IsNotExist(err) {
-		log.Printf("Migration path %s does not exist, skipping migrations", migrationPath)
-		return nil
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("migration path %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("migration path %s is not a directory", path)
 	}
 
-	migrator := NewMigrator(db, migrationPath)
+	return os.DirFS(path), nil
+}
+
+func AutoMigrate(db *sqlx.DB, fsys fs.FS) error {
+	migrator := NewMigrator(db, fsys)
 	return migrator.RunMigrations()
 }
+
+func Verify(db *sqlx.DB, fsys fs.FS) error {
+	migrator := NewMigrator(db, fsys)
+	return migrator.Verify()
+}
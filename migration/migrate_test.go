@@ -0,0 +1,107 @@
+package migration
+
+import (
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "plain statements",
+			content: "CREATE TABLE a (id INT); DROP TABLE b;",
+			want:    []string{"CREATE TABLE a (id INT)", "DROP TABLE b"},
+		},
+		{
+			name:    "semicolon in quotes",
+			content: `INSERT INTO a VALUES ('x;y', 'it''s;'); SELECT "odd;name" FROM a;`,
+			want:    []string{`INSERT INTO a VALUES ('x;y', 'it''s;')`, `SELECT "odd;name" FROM a`},
+		},
+		{
+			name:    "line comment",
+			content: "-- drop a; then b\nDROP TABLE b;",
+			want:    []string{"-- drop a; then b\nDROP TABLE b"},
+		},
+		{
+			name:    "block comment",
+			content: "/* first; second */ DROP TABLE b; /* unterminated; ",
+			want:    []string{"/* first; second */ DROP TABLE b", "/* unterminated;"},
+		},
+		{
+			name:    "dollar-quoted block",
+			content: "DO $$ BEGIN DELETE FROM a; END $$; SELECT 1;",
+			want:    []string{"DO $$ BEGIN DELETE FROM a; END $$", "SELECT 1"},
+		},
+		{
+			name:    "nested dollar tags",
+			content: "CREATE FUNCTION f() RETURNS void AS $fn$ BEGIN EXECUTE $q$DELETE FROM a; $$ $q$; END $fn$ LANGUAGE plpgsql; SELECT 1;",
+			want:    []string{"CREATE FUNCTION f() RETURNS void AS $fn$ BEGIN EXECUTE $q$DELETE FROM a; $$ $q$; END $fn$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			name:    "positional parameter is not a tag",
+			content: "SELECT $1; SELECT 2;",
+			want:    []string{"SELECT $1", "SELECT 2"},
+		},
+		{
+			name:    "escape string",
+			content: `SELECT E'it\'s;', e'a\\'; SELECT 2;`,
+			want:    []string{`SELECT E'it\'s;', e'a\\'`, "SELECT 2"},
+		},
+		{
+			name:    "escape string with doubled quote",
+			content: `SELECT E'a'';b'; SELECT 2;`,
+			want:    []string{`SELECT E'a'';b'`, "SELECT 2"},
+		},
+		{
+			name:    "identifier ending in e before a string",
+			content: `SELECT name'\'; SELECT 2;`,
+			want:    []string{`SELECT name'\'`, "SELECT 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimmed(splitStatements(tt.content)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSplitSkillTaxonomy checks that the merge loop of migration 10 reaches
+// the database as one statement.
+func TestSplitSkillTaxonomy(t *testing.T) {
+	content, err := fs.ReadFile(Files, "10_skill_taxonomy.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var blocks []string
+	for _, statement := range trimmed(splitStatements(string(content))) {
+		if strings.Contains(statement, "DO $$") {
+			blocks = append(blocks, statement)
+		}
+	}
+
+	if len(blocks) != 1 || !strings.HasSuffix(blocks[0], "END\n$$") {
+		t.Errorf("DO blocks = %q, want one ending in END $$", blocks)
+	}
+}
+
+// trimmed drops the whitespace around statements and the empty ones, as
+// executeSQL does.
+func trimmed(statements []string) []string {
+	var out []string
+	for _, statement := range statements {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			out = append(out, statement)
+		}
+	}
+	return out
+}
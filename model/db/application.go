@@ -0,0 +1,14 @@
+package db
+
+import "github.com/lib/pq"
+
+type Application struct {
+	UUID            string         `db:"uuid"`
+	ProjectUUID     string         `db:"project_uuid"`
+	StudentUUID     string         `db:"student_uuid"`
+	Status          string         `db:"status"`
+	CoverLetter     string         `db:"cover_letter"`
+	AttachmentUUIDs pq.StringArray `db:"attachment_uuids"`
+	CreatedAt       string         `db:"created_at"`
+	UpdatedAt       string         `db:"updated_at"`
+}
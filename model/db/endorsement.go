@@ -0,0 +1,16 @@
+package db
+
+type Endorsement struct {
+	UUID         string `db:"uuid"`
+	StudentUUID  string `db:"student_uuid"`
+	SkillUUID    string `db:"skill_uuid"`
+	BusinessUUID string `db:"business_uuid"`
+	ProjectUUID  string `db:"project_uuid"`
+	Comment      string `db:"comment"`
+	CreatedAt    string `db:"created_at"`
+
+	// Joined for display by the read queries.
+	SkillName   string `db:"skill_name"`
+	CompanyName string `db:"company_name"`
+	ProjectName string `db:"project_name"`
+}
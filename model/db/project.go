@@ -10,4 +10,8 @@ type Project struct {
 	Deliverables string `db:"deliverables"`
 	CreatedBy    string `db:"created_by"`
 	CreatedAt    string `db:"created_at"`
+
+	// Rank and Snippet are only populated by full-text searches.
+	Rank    *float64 `db:"rank"`
+	Snippet *string  `db:"snippet"`
 }
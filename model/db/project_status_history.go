@@ -0,0 +1,10 @@
+package db
+
+type ProjectStatusHistory struct {
+	UUID        string  `db:"uuid"`
+	ProjectUUID string  `db:"project_uuid"`
+	FromStatus  *string `db:"from_status"`
+	ToStatus    string  `db:"to_status"`
+	ChangedBy   *string `db:"changed_by"`
+	ChangedAt   string  `db:"changed_at"`
+}
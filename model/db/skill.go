@@ -1,8 +1,9 @@
 package db
 
 type Skill struct {
-	UUID        string `db:"uuid"`
-	Name        string `db:"name"`
-	Description string `db:"description"`
-	CreatedAt   string `db:"created_at"`
+	UUID         string  `db:"uuid"`
+	Name         string  `db:"name"`
+	Description  string  `db:"description"`
+	CategoryUUID *string `db:"category_uuid"`
+	CreatedAt    string  `db:"created_at"`
 }
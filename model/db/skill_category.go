@@ -0,0 +1,8 @@
+package db
+
+type SkillCategory struct {
+	UUID       string  `db:"uuid"`
+	Name       string  `db:"name"`
+	ParentUUID *string `db:"parent_uuid"`
+	CreatedAt  string  `db:"created_at"`
+}
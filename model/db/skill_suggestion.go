@@ -0,0 +1,10 @@
+package db
+
+type SkillSuggestion struct {
+	UUID         string  `db:"uuid"`
+	Name         string  `db:"name"`
+	MatchedAlias *string `db:"matched_alias"`
+	Score        float64 `db:"score"`
+	ProjectCount int     `db:"project_count"`
+	StudentCount int     `db:"student_count"`
+}
@@ -0,0 +1,13 @@
+package db
+
+// StudentSkill is a row of student_skills joined with the skill name and the
+// number of endorsements the student holds for it.
+type StudentSkill struct {
+	StudentUUID      string `db:"student_uuid"`
+	SkillUUID        string `db:"skill_uuid"`
+	SkillName        string `db:"skill_name"`
+	SkillDescription string `db:"skill_description"`
+	Proficiency      string `db:"proficiency"`
+	YearsExperience  int    `db:"years_experience"`
+	EndorsementCount int    `db:"endorsement_count"`
+}
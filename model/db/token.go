@@ -0,0 +1,13 @@
+package db
+
+import "time"
+
+type RefreshToken struct {
+	UUID       string     `db:"uuid"`
+	UserUUID   string     `db:"user_uuid"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *string    `db:"replaced_by"`
+	CreatedAt  string     `db:"created_at"`
+}
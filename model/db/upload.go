@@ -0,0 +1,23 @@
+package db
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// FileUpload is a resumable upload in progress. ChunkKeys lists the stored
+// chunks in offset order.
+type FileUpload struct {
+	UUID         string         `db:"uuid"`
+	UploadedBy   string         `db:"uploaded_by"`
+	Purpose      string         `db:"purpose"`
+	FileName     string         `db:"file_name"`
+	UploadLength int64          `db:"upload_length"`
+	UploadOffset int64          `db:"upload_offset"`
+	ChunkKeys    pq.StringArray `db:"chunk_keys"`
+	FileUUID     *string        `db:"file_uuid"`
+	CompletedAt  *time.Time     `db:"completed_at"`
+	CreatedAt    string         `db:"created_at"`
+	UpdatedAt    string         `db:"updated_at"`
+}
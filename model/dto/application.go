@@ -0,0 +1,17 @@
+package dto
+
+type ApplicationRequest struct {
+	CoverLetter     string   `json:"cover_letter"`
+	AttachmentUUIDs []string `json:"attachment_uuids" binding:"omitempty,dive,uuid"`
+}
+
+type ApplicationResponse struct {
+	UUID            string   `json:"uuid"`
+	ProjectUUID     string   `json:"project_uuid"`
+	StudentUUID     string   `json:"student_uuid"`
+	Status          string   `json:"status"`
+	CoverLetter     string   `json:"cover_letter"`
+	AttachmentUUIDs []string `json:"attachment_uuids"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
@@ -8,4 +8,7 @@ type BusinessResponse struct {
 	UUID        string `json:"uuid"`
 	UserUUID    string `json:"user_uuid"`
 	CompanyName string `json:"company_name"`
+	// Tokens is only set on creation and carries the new business in its
+	// claims, so the owner can manage it without refreshing first.
+	Tokens *LoginResponse `json:"tokens,omitempty"`
 }
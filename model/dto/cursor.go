@@ -0,0 +1,13 @@
+package dto
+
+// MaxPageLimit caps the page size of the list endpoints; larger limits are
+// clamped to it.
+const MaxPageLimit = 100
+
+// Cursor is the decoded position of the last row of a keyset page. Key holds
+// the primary sort value (created_at for projects, email for students) and
+// UUID breaks ties between rows sharing the same key.
+type Cursor struct {
+	Key  string `json:"k"`
+	UUID string `json:"id"`
+}
@@ -7,6 +7,34 @@ type FileUploadResponse struct {
 	FilePath     string `json:"file_path"`
 	FileSize     int64  `json:"file_size"`
 	MimeType     string `json:"mime_type"`
+	Purpose      string `json:"purpose"`
 	URL          string `json:"url"`
 	CreatedAt    string `json:"created_at"`
+	// Variants holds the resized copies of an image keyed by variant name
+	// (thumbnail, medium, original).
+	Variants map[string]*FileVariantResponse `json:"variants,omitempty"`
+}
+
+type FileVariantResponse struct {
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size"`
+	MimeType string `json:"mime_type"`
+}
+
+type AttachFileRequest struct {
+	FileUUID string `json:"file_uuid" binding:"required,uuid"`
+}
+
+// UploadResponse describes a resumable upload. File is set once every byte
+// has arrived and the upload was stored.
+type UploadResponse struct {
+	UUID      string              `json:"uuid"`
+	FileName  string              `json:"file_name"`
+	Purpose   string              `json:"purpose"`
+	Length    int64               `json:"length"`
+	Offset    int64               `json:"offset"`
+	File      *FileUploadResponse `json:"file,omitempty"`
+	CreatedAt string              `json:"created_at"`
 }
@@ -0,0 +1,26 @@
+package dto
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFailed   = "failed"
+	HealthStatusReady    = "ready"
+	HealthStatusNotReady = "not_ready"
+)
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse reports the outcome of each readiness check, keyed by
+// check name, as "ok" or "failed".
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
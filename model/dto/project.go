@@ -6,6 +6,7 @@ type ProjectRequest struct {
 	Duration     int      `json:"duration" binding:"required"`
 	Timeline     string   `json:"timeline" binding:"required,oneof=day week month year"`
 	Deliverables string   `json:"deliverables" binding:"required"`
+	Status       string   `json:"status" binding:"omitempty,oneof=draft open"`
 	Skills       []string `json:"skills"`
 }
 
@@ -20,29 +21,56 @@ type ProjectResponse struct {
 	Skills       []string `json:"skills"`
 	CreatedBy    string   `json:"created_by"`
 	CreatedAt    string   `json:"created_at"`
+	Rank         *float64 `json:"rank,omitempty"`
+	// Snippet is escaped HTML with the matched terms wrapped in <mark>.
+	Snippet *string `json:"snippet,omitempty"`
 }
 
 type ProjectUpdateRequest struct {
 	Name         string `json:"name"`
 	Description  string `json:"description"`
-	Status       string `json:"status" binding:"omitempty,oneof=open in_progress completed"`
+	Status       string `json:"status" binding:"omitempty,oneof=draft open in_progress completed cancelled archived"`
 	Duration     *int   `json:"duration"`
 	Timeline     string `json:"timeline" binding:"omitempty,oneof=day week month year"`
 	Deliverables string `json:"deliverables"`
 }
 
+// ProjectListRequest supports two pagination modes. Page/Limit is the
+// original offset mode; a non-nil Cursor switches to keyset mode, where Page
+// is not needed and an empty cursor requests the first page. Sort is
+// "recent" (the default) or "relevance", which needs a Search term and only
+// works in page mode.
 type ProjectListRequest struct {
 	Skill  *string `json:"skill"`
 	Budget *int    `json:"budget"`
 	Search *string `json:"search"`
-	Page   int     `json:"page" binding:"required"`
+	Sort   string  `json:"sort"`
+	Page   int     `json:"page" binding:"required_without=Cursor"`
 	Limit  int     `json:"limit" binding:"required"`
+	Cursor *string `json:"cursor"`
+	After  *Cursor `json:"-"`
 }
 
+// ProjectListResponse carries TotalCount and TotalPages in page mode only;
+// in cursor mode NextCursor is set while more results remain.
 type ProjectListResponse struct {
 	Projects   []*ProjectResponse `json:"projects"`
 	TotalCount int                `json:"total_count"`
 	Page       int                `json:"page"`
 	Limit      int                `json:"limit"`
 	TotalPages int                `json:"total_pages"`
+	NextCursor *string            `json:"next_cursor,omitempty"`
 }
+
+type ProjectStatusHistoryResponse struct {
+	UUID       string  `json:"uuid"`
+	FromStatus *string `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+	ChangedBy  *string `json:"changed_by"`
+	ChangedAt  string  `json:"changed_at"`
+}
+
+const (
+	ProjectSortRecent    = "recent"
+	ProjectSortRelevance = "relevance"
+)
@@ -0,0 +1,33 @@
+package dto
+
+// SkillScore is one required project skill and its contribution to a
+// recommendation score.
+type SkillScore struct {
+	Skill   string  `json:"skill"`
+	Weight  float64 `json:"weight"`
+	Matched bool    `json:"matched"`
+}
+
+// ScoreBreakdown explains a recommendation score: Score is MatchedWeight
+// divided by TotalWeight, where each project skill is weighted by how rare
+// it is among students.
+type ScoreBreakdown struct {
+	MatchedWeight float64       `json:"matched_weight"`
+	TotalWeight   float64       `json:"total_weight"`
+	MatchedSkills int           `json:"matched_skills"`
+	TotalSkills   int           `json:"total_skills"`
+	Skills        []*SkillScore `json:"skills"`
+}
+
+type ProjectRecommendation struct {
+	Project   *ProjectResponse `json:"project"`
+	Score     float64          `json:"score"`
+	Breakdown *ScoreBreakdown  `json:"breakdown"`
+}
+
+type StudentRecommendation struct {
+	Student   *StudentResponse `json:"student"`
+	Skills    []string         `json:"skills"`
+	Score     float64          `json:"score"`
+	Breakdown *ScoreBreakdown  `json:"breakdown"`
+}
@@ -1,17 +1,53 @@
 package dto
 
 type SkillRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name         string  `json:"name" binding:"required"`
+	Description  string  `json:"description"`
+	CategoryUUID *string `json:"category_uuid" binding:"omitempty,uuid"`
 }
 
 type SkillResponse struct {
-	UUID        string `json:"uuid"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
+	UUID         string   `json:"uuid"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	CategoryUUID *string  `json:"category_uuid"`
+	Aliases      []string `json:"aliases,omitempty"`
+	CreatedAt    string   `json:"created_at"`
 }
 
 type SkillNameRequest struct {
 	SkillName string `json:"skill_name" binding:"required"`
 }
+
+type SkillAliasRequest struct {
+	Alias string `json:"alias" binding:"required,max=100"`
+}
+
+// SkillMergeRequest names the duplicate skill to fold into the skill in the
+// URL.
+type SkillMergeRequest struct {
+	SourceUUID string `json:"source_uuid" binding:"required,uuid"`
+}
+
+type SkillCategoryRequest struct {
+	Name       string  `json:"name" binding:"required,max=100"`
+	ParentUUID *string `json:"parent_uuid" binding:"omitempty,uuid"`
+}
+
+type SkillCategoryResponse struct {
+	UUID       string  `json:"uuid"`
+	Name       string  `json:"name"`
+	ParentUUID *string `json:"parent_uuid"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// SkillSuggestionResponse is one autocomplete hit. MatchedAlias is set when
+// the term matched an alias rather than the canonical name.
+type SkillSuggestionResponse struct {
+	UUID         string  `json:"uuid"`
+	Name         string  `json:"name"`
+	MatchedAlias *string `json:"matched_alias,omitempty"`
+	Score        float64 `json:"score"`
+	ProjectCount int     `json:"project_count"`
+	StudentCount int     `json:"student_count"`
+}
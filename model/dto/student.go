@@ -8,4 +8,63 @@ type StudentResponse struct {
 	UUID       string `json:"uuid"`
 	UserUUID   string `json:"user_uuid"`
 	University string `json:"university"`
+	// Tokens is only set on creation and carries the new student in its
+	// claims, so the owner can manage it without refreshing first.
+	Tokens *LoginResponse `json:"tokens,omitempty"`
+}
+
+// Proficiency levels for a student skill, lowest first.
+const (
+	ProficiencyBeginner     = "beginner"
+	ProficiencyIntermediate = "intermediate"
+	ProficiencyAdvanced     = "advanced"
+	ProficiencyExpert       = "expert"
+)
+
+var ProficiencyLevels = []string{ProficiencyBeginner, ProficiencyIntermediate, ProficiencyAdvanced, ProficiencyExpert}
+
+// ProficienciesFrom returns min and every level above it, or nil when min is
+// not a known level.
+func ProficienciesFrom(min string) []string {
+	for i, level := range ProficiencyLevels {
+		if level == min {
+			return ProficiencyLevels[i:]
+		}
+	}
+	return nil
+}
+
+// StudentSkillRequest adds a skill or updates one the student has. Omitted
+// fields keep their current values, or the defaults for a new skill.
+type StudentSkillRequest struct {
+	SkillName       string  `json:"skill_name" binding:"required"`
+	Proficiency     *string `json:"proficiency" binding:"omitempty,oneof=beginner intermediate advanced expert"`
+	YearsExperience *int    `json:"years_experience" binding:"omitempty,min=0,max=60"`
+}
+
+type StudentSkillResponse struct {
+	UUID             string `json:"uuid"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Proficiency      string `json:"proficiency"`
+	YearsExperience  int    `json:"years_experience"`
+	EndorsementCount int    `json:"endorsement_count"`
+}
+
+type EndorsementRequest struct {
+	SkillName   string `json:"skill_name" binding:"required"`
+	ProjectUUID string `json:"project_uuid" binding:"required,uuid"`
+	Comment     string `json:"comment" binding:"max=1000"`
+}
+
+type EndorsementResponse struct {
+	UUID         string `json:"uuid"`
+	StudentUUID  string `json:"student_uuid"`
+	Skill        string `json:"skill"`
+	BusinessUUID string `json:"business_uuid"`
+	CompanyName  string `json:"company_name"`
+	ProjectUUID  string `json:"project_uuid"`
+	ProjectName  string `json:"project_name"`
+	Comment      string `json:"comment"`
+	CreatedAt    string `json:"created_at"`
 }
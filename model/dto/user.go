@@ -5,6 +5,18 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
+type LoginResponse struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int64        `json:"expires_in"`
+	User         UserResponse `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type RegisterRequest struct {
 	Email       string  `json:"email" binding:"required,email"`
 	Name        string  `json:"name" binding:"required"`
@@ -33,12 +45,19 @@ type UserDetailResponse struct {
 	Skills      []string `json:"skills,omitempty"`
 }
 
+// StudentListRequest paginates like ProjectListRequest: a non-nil Cursor
+// selects keyset mode. MinProficiency and Endorsed narrow the Skill filter,
+// or apply to any of the student's skills when Skill is not set.
 type StudentListRequest struct {
-	Name       *string `json:"name"`
-	University *string `json:"university"`
-	Skill      *string `json:"skill"`
-	Page       int     `json:"page" binding:"required"`
-	Limit      int     `json:"limit" binding:"required"`
+	Name           *string `json:"name"`
+	University     *string `json:"university"`
+	Skill          *string `json:"skill"`
+	MinProficiency *string `json:"min_proficiency"`
+	Endorsed       bool    `json:"endorsed"`
+	Page           int     `json:"page" binding:"required_without=Cursor"`
+	Limit          int     `json:"limit" binding:"required"`
+	Cursor         *string `json:"cursor"`
+	After          *Cursor `json:"-"`
 }
 
 type StudentListResponse struct {
@@ -47,14 +66,17 @@ type StudentListResponse struct {
 	Page       int                      `json:"page"`
 	Limit      int                      `json:"limit"`
 	TotalPages int                      `json:"total_pages"`
+	NextCursor *string                  `json:"next_cursor,omitempty"`
 }
 
 type StudentDetailResponse struct {
-	UUID       string   `json:"uuid"`
-	UserUUID   string   `json:"user_uuid"`
-	Email      string   `json:"email"`
-	Name       string   `json:"name"`
-	University string   `json:"university"`
-	Skills     []string `json:"skills"`
-	CreatedAt  string   `json:"created_at"`
+	UUID             string                  `json:"uuid"`
+	UserUUID         string                  `json:"user_uuid"`
+	Email            string                  `json:"email"`
+	Name             string                  `json:"name"`
+	University       string                  `json:"university"`
+	Skills           []string                `json:"skills"`
+	SkillDetails     []*StudentSkillResponse `json:"skill_details"`
+	EndorsementCount int                     `json:"endorsement_count"`
+	CreatedAt        string                  `json:"created_at"`
 }
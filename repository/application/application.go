@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type IApplication interface {
+	Create(ctx context.Context, tx *sqlx.Tx, application *db.Application) error
+	GetByUUID(ctx context.Context, uuid string) (*db.Application, error)
+	UpdateStatus(ctx context.Context, tx *sqlx.Tx, application *db.Application, from string) error
+	MarkRemoved(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error
+	GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.Application, error)
+	GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Application, error)
+}
+
+type Application struct {
+	db *sqlx.DB
+}
+
+func NewApplication(db *sqlx.DB) IApplication {
+	return &Application{
+		db: db,
+	}
+}
+
+func (a *Application) Create(ctx context.Context, tx *sqlx.Tx, application *db.Application) error {
+	err := tx.QueryRowContext(ctx, CreateQuery,
+		application.ProjectUUID,
+		application.StudentUUID,
+		application.CoverLetter,
+		application.AttachmentUUIDs,
+	).Scan(&application.UUID, &application.Status, &application.CreatedAt, &application.UpdatedAt)
+	if constant.IsUniqueViolation(err) {
+		return constant.ErrAlreadyApplied
+	}
+	return constant.FromDB(err, "application")
+}
+
+func (a *Application) GetByUUID(ctx context.Context, uuid string) (*db.Application, error) {
+	application := &db.Application{}
+	err := a.db.GetContext(ctx, application, GetByUUIDQuery, uuid)
+	return application, constant.FromDB(err, "application")
+}
+
+// UpdateStatus moves an application to application.Status, but only while it
+// is still in the status it was checked in. Concurrent changes fail with
+// ErrInvalidTransition instead of overwriting each other.
+func (a *Application) UpdateStatus(ctx context.Context, tx *sqlx.Tx, application *db.Application, from string) error {
+	err := tx.QueryRowContext(ctx, UpdateStatusQuery, application.Status, application.UUID, from).Scan(&application.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return constant.ErrInvalidTransition
+	}
+	return constant.FromDB(err, "application")
+}
+
+// MarkRemoved moves the student's accepted application on the project to
+// removed. It is a no-op when there is none.
+func (a *Application) MarkRemoved(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error {
+	_, err := tx.ExecContext(ctx, MarkRemovedQuery, projectUUID, studentUUID)
+	return constant.FromDB(err, "application")
+}
+
+func (a *Application) GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.Application, error) {
+	var applications []*db.Application
+	err := a.db.SelectContext(ctx, &applications, GetByProjectUUIDQuery, projectUUID)
+	return applications, constant.FromDB(err, "application")
+}
+
+func (a *Application) GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Application, error) {
+	var applications []*db.Application
+	err := a.db.SelectContext(ctx, &applications, GetByStudentUUIDQuery, studentUUID)
+	return applications, constant.FromDB(err, "application")
+}
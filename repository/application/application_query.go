@@ -0,0 +1,39 @@
+package application
+
+const (
+	CreateQuery = `
+		INSERT INTO project_applications (project_uuid, student_uuid, cover_letter, attachment_uuids)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid, status, created_at, updated_at
+	`
+
+	GetByUUIDQuery = `
+		SELECT uuid, project_uuid, student_uuid, status, cover_letter, attachment_uuids, created_at, updated_at
+		FROM project_applications WHERE uuid = $1
+	`
+
+	UpdateStatusQuery = `
+		UPDATE project_applications
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE uuid = $2 AND status = $3
+		RETURNING updated_at
+	`
+
+	// MarkRemovedQuery closes the accepted application of a student who was
+	// removed from the project, so they may apply again.
+	MarkRemovedQuery = `
+		UPDATE project_applications
+		SET status = 'removed', updated_at = CURRENT_TIMESTAMP
+		WHERE project_uuid = $1 AND student_uuid = $2 AND status = 'accepted'
+	`
+
+	GetByProjectUUIDQuery = `
+		SELECT uuid, project_uuid, student_uuid, status, cover_letter, attachment_uuids, created_at, updated_at
+		FROM project_applications WHERE project_uuid = $1 ORDER BY created_at DESC
+	`
+
+	GetByStudentUUIDQuery = `
+		SELECT uuid, project_uuid, student_uuid, status, cover_letter, attachment_uuids, created_at, updated_at
+		FROM project_applications WHERE student_uuid = $1 ORDER BY created_at DESC
+	`
+)
@@ -1,17 +1,20 @@
 package business
 
 import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/jmoiron/sqlx"
 )
 
 type IBusiness interface {
-	Create(tx *sqlx.Tx, business *db.Business) error
-	GetByUUID(uuid string) (*db.Business, error)
-	GetByUserUUID(userUUID string) (*db.Business, error)
-	Update(tx *sqlx.Tx, business *db.Business) error
-	Delete(tx *sqlx.Tx, uuid string) error
-	GetAll() ([]*db.Business, error)
+	Create(ctx context.Context, tx *sqlx.Tx, business *db.Business) error
+	GetByUUID(ctx context.Context, uuid string) (*db.Business, error)
+	GetByUserUUID(ctx context.Context, userUUID string) (*db.Business, error)
+	Update(ctx context.Context, tx *sqlx.Tx, business *db.Business) error
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error
+	GetAll(ctx context.Context) ([]*db.Business, error)
 }
 
 type Business struct {
@@ -24,34 +27,35 @@ func NewBusiness(db *sqlx.DB) IBusiness {
 	}
 }
 
-func (b *Business) Create(tx *sqlx.Tx, business *db.Business) error {
-	return tx.QueryRow(CreateQuery, business.UserUUID, business.CompanyName).Scan(&business.UUID)
+func (b *Business) Create(ctx context.Context, tx *sqlx.Tx, business *db.Business) error {
+	err := tx.QueryRowContext(ctx, CreateQuery, business.UserUUID, business.CompanyName).Scan(&business.UUID)
+	return constant.FromDB(err, "business")
 }
 
-func (b *Business) GetByUUID(uuid string) (*db.Business, error) {
+func (b *Business) GetByUUID(ctx context.Context, uuid string) (*db.Business, error) {
 	business := &db.Business{}
-	err := b.db.Get(business, GetByUUIDQuery, uuid)
-	return business, err
+	err := b.db.GetContext(ctx, business, GetByUUIDQuery, uuid)
+	return business, constant.FromDB(err, "business")
 }
 
-func (b *Business) GetByUserUUID(userUUID string) (*db.Business, error) {
+func (b *Business) GetByUserUUID(ctx context.Context, userUUID string) (*db.Business, error) {
 	business := &db.Business{}
-	err := b.db.Get(business, GetByUserUUIDQuery, userUUID)
-	return business, err
+	err := b.db.GetContext(ctx, business, GetByUserUUIDQuery, userUUID)
+	return business, constant.FromDB(err, "business")
 }
 
-func (b *Business) Update(tx *sqlx.Tx, business *db.Business) error {
-	_, err := tx.Exec(UpdateQuery, business.CompanyName, business.UUID)
-	return err
+func (b *Business) Update(ctx context.Context, tx *sqlx.Tx, business *db.Business) error {
+	_, err := tx.ExecContext(ctx, UpdateQuery, business.CompanyName, business.UUID)
+	return constant.FromDB(err, "business")
 }
 
-func (b *Business) Delete(tx *sqlx.Tx, uuid string) error {
-	_, err := tx.Exec(DeleteQuery, uuid)
-	return err
+func (b *Business) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error {
+	_, err := tx.ExecContext(ctx, DeleteQuery, uuid)
+	return constant.FromDB(err, "business")
 }
 
-func (b *Business) GetAll() ([]*db.Business, error) {
+func (b *Business) GetAll(ctx context.Context) ([]*db.Business, error) {
 	var businesses []*db.Business
-	err := b.db.Select(&businesses, GetAllQuery)
-	return businesses, err
+	err := b.db.SelectContext(ctx, &businesses, GetAllQuery)
+	return businesses, constant.FromDB(err, "business")
 }
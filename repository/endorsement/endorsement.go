@@ -0,0 +1,44 @@
+package endorsement
+
+import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type IEndorsement interface {
+	Create(ctx context.Context, tx *sqlx.Tx, endorsement *db.Endorsement) error
+	GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Endorsement, error)
+}
+
+type Endorsement struct {
+	db *sqlx.DB
+}
+
+func NewEndorsement(db *sqlx.DB) IEndorsement {
+	return &Endorsement{
+		db: db,
+	}
+}
+
+func (e *Endorsement) Create(ctx context.Context, tx *sqlx.Tx, endorsement *db.Endorsement) error {
+	err := tx.QueryRowContext(ctx, CreateQuery,
+		endorsement.StudentUUID,
+		endorsement.SkillUUID,
+		endorsement.BusinessUUID,
+		endorsement.ProjectUUID,
+		endorsement.Comment,
+	).Scan(&endorsement.UUID, &endorsement.CreatedAt)
+	if constant.IsUniqueViolation(err) {
+		return constant.ErrAlreadyEndorsed
+	}
+	return constant.FromDB(err, "endorsement")
+}
+
+func (e *Endorsement) GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Endorsement, error) {
+	var endorsements []*db.Endorsement
+	err := e.db.SelectContext(ctx, &endorsements, GetByStudentUUIDQuery, studentUUID)
+	return endorsements, constant.FromDB(err, "endorsement")
+}
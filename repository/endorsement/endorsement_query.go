@@ -0,0 +1,29 @@
+package endorsement
+
+const (
+	CreateQuery = `
+		INSERT INTO skill_endorsements (student_uuid, skill_uuid, business_uuid, project_uuid, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING uuid, created_at
+	`
+
+	GetByStudentUUIDQuery = `
+		SELECT
+			se.uuid,
+			se.student_uuid,
+			se.skill_uuid,
+			se.business_uuid,
+			se.project_uuid,
+			se.comment,
+			se.created_at,
+			s.name AS skill_name,
+			COALESCE(b.company_name, '') AS company_name,
+			p.name AS project_name
+		FROM skill_endorsements se
+		INNER JOIN skills s ON s.uuid = se.skill_uuid
+		INNER JOIN businesses b ON b.uuid = se.business_uuid
+		INNER JOIN projects p ON p.uuid = se.project_uuid
+		WHERE se.student_uuid = $1
+		ORDER BY se.created_at DESC
+	`
+)
@@ -1,145 +1,372 @@
 package file
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/storage"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type IFile interface {
-	UploadImage(tx *sqlx.Tx, file multipart.File, header *multipart.FileHeader, uploadedBy string) (*db.File, error)
-	GetByUUID(uuid string) (*db.File, error)
-	Delete(tx *sqlx.Tx, uuid string) error
-	GetByUploadedBy(uploadedBy string) ([]*db.File, error)
+	Upload(ctx context.Context, tx *sqlx.Tx, r io.Reader, size int64, name, uploadedBy, purpose string) (*db.File, []string, error)
+	Precheck(r io.Reader, size int64, purpose string) (io.Reader, error)
+	GetByUUID(ctx context.Context, uuid string) (*db.File, error)
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) ([]string, error)
+	DeleteObjects(ctx context.Context, keys []string) error
+	GetByUploadedBy(ctx context.Context, uploadedBy string) ([]*db.File, error)
+	GetVariants(ctx context.Context, fileUUIDs []string) (map[string][]*db.File, error)
+	URL(ctx context.Context, file *db.File) (string, error)
+	AttachToStudent(ctx context.Context, tx *sqlx.Tx, studentUUID, fileUUID string) error
+	DetachFromStudent(ctx context.Context, tx *sqlx.Tx, studentUUID, fileUUID string) error
+	GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.File, error)
+	AttachToProject(ctx context.Context, tx *sqlx.Tx, projectUUID, fileUUID string) error
+	DetachFromProject(ctx context.Context, tx *sqlx.Tx, projectUUID, fileUUID string) error
+	GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.File, error)
 }
 
 type File struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	storage   storage.IStorage
+	urlExpiry time.Duration
+	image     config.ImageConfig
+	// processing holds a slot for every image being processed.
+	processing chan struct{}
 }
 
-func NewFile(db *sqlx.DB) IFile {
+func NewFile(db *sqlx.DB, storage storage.IStorage, urlExpiry time.Duration, image config.ImageConfig) IFile {
 	return &File{
-		db: db,
+		db:         db,
+		storage:    storage,
+		urlExpiry:  urlExpiry,
+		image:      image,
+		processing: make(chan struct{}, max(1, image.MaxConcurrent)),
 	}
 }
 
-func (f *File) UploadImage(tx *sqlx.Tx, file multipart.File, header *multipart.FileHeader, uploadedBy string) (*db.File, error) {
-	defer file.Close()
+// Upload validates a file against its purpose's policy and stores it.
+// Images are stored as re-encoded variants. Other files are streamed to
+// storage as uploaded, so large ones are never held in memory; size must be
+// the exact length of r.
+//
+// It returns the keys of the objects it wrote, also when it fails. Storage
+// is not part of tx, so the caller passes them to DeleteObjects unless tx
+// commits.
+func (f *File) Upload(ctx context.Context, tx *sqlx.Tx, r io.Reader, size int64, name, uploadedBy, purpose string) (*db.File, []string, error) {
+	br, mimeType, err := sniff(r, size, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Validate file type
-	if !f.isValidImageType(header.Filename) {
-		return nil, fmt.Errorf("invalid file type. Only jpg, jpeg, png, gif are allowed")
+	fileRecord := &db.File{
+		UUID:         uuid.New().String(),
+		OriginalName: name,
+		UploadedBy:   uploadedBy,
+		Purpose:      purpose,
 	}
 
-	id := uuid.New()
-	ext := filepath.Ext(header.Filename)
-	if ext == "" {
-		ext = ".png"
+	if !isImage(mimeType) {
+		var content io.Reader = br
+		var check func() error
+
+		// PDFs are checked for truncation once their end has streamed past
+		if mimeType == pdfType {
+			tail := &trailer{n: pdfTrailerLen}
+			content = io.TeeReader(br, tail)
+			check = func() error { return checkPDF(tail.buf) }
+		}
+
+		keys, err := f.storeDocument(ctx, tx, fileRecord, content, size, mimeType, check)
+		if err != nil {
+			return nil, keys, err
+		}
+		return fileRecord, keys, nil
 	}
 
-	filename := fmt.Sprintf("%s%s", id.String(), ext)
-	uploadDir := "./static/images"
-	filePath := filepath.Join(uploadDir, filename)
+	// Images are decoded as a whole; MaxSizeFor keeps them small enough
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %v", err)
+	}
 
-	// Create upload directory
-	err := os.MkdirAll(uploadDir, os.ModePerm)
+	if err := checkImage(bytes.NewReader(data)); err != nil {
+		return nil, nil, err
+	}
+	keys, err := f.storeImage(ctx, tx, fileRecord, data, mimeType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create upload directory: %v", err)
+		return nil, keys, err
 	}
 
-	// Create file
-	dst, err := os.Create(filePath)
+	return fileRecord, keys, nil
+}
+
+// Precheck runs the checks Upload can make from the head of a file of size
+// bytes, so a resumable upload is rejected on its first chunk rather than
+// after its last. The returned reader still yields all of r.
+func (f *File) Precheck(r io.Reader, size int64, purpose string) (io.Reader, error) {
+	br, _, err := sniff(r, size, purpose)
+	return br, err
+}
+
+// sniff detects the type of a file of size bytes from its head and checks
+// both against the purpose's policy.
+func sniff(r io.Reader, size int64, purpose string) (*bufio.Reader, string, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	policy := config.UploadPolicies[purpose]
+	mimeType, err := detect(head, policy.MimeTypes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %v", err)
+		return nil, "", err
+	}
+	if maxSize := policy.MaxSizeFor(mimeType); size > maxSize {
+		return nil, "", constant.FileTooLarge(size, maxSize)
 	}
-	defer dst.Close()
 
-	// Copy file data
-	size, err := io.Copy(dst, file)
+	return br, mimeType, nil
+}
+
+// storeImage stores the variants of an image and records them as children
+// of its row. Only re-encoded copies are stored, so the uploaded bytes and
+// their metadata never reach storage; the image's own row points at the
+// full-size "original" variant. It returns the keys it stored.
+func (f *File) storeImage(ctx context.Context, tx *sqlx.Tx, fileRecord *db.File, data []byte, sourceType string) ([]string, error) {
+	select {
+	case f.processing <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	mimeType, ext, images, err := processImage(data, sourceType, f.image)
+	<-f.processing
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy file: %v", err)
+		return nil, err
 	}
 
-	// Create file record
-	fileRecord := &db.File{
-		UUID:         id.String(),
-		OriginalName: header.Filename,
-		FileName:     filename,
-		FilePath:     filePath,
-		FileSize:     size,
-		MimeType:     header.Header.Get("Content-Type"),
-		UploadedBy:   uploadedBy,
+	id := fileRecord.UUID
+	fileRecord.FileName = id + ext
+	fileRecord.FilePath = "images/" + id + ext
+	fileRecord.MimeType = mimeType
+
+	var stored []string
+	for _, img := range images {
+		variant := img.variant
+		width, height := img.width, img.height
+		filename := fmt.Sprintf("%s_%s%s", id, variant, ext)
+		if variant == VariantOriginal {
+			filename = fileRecord.FileName
+			fileRecord.FileSize = int64(len(img.data))
+			fileRecord.Width, fileRecord.Height = &width, &height
+		}
+
+		child := &db.File{
+			UUID:         uuid.New().String(),
+			OriginalName: fileRecord.OriginalName,
+			FileName:     filename,
+			FilePath:     "images/" + filename,
+			FileSize:     int64(len(img.data)),
+			MimeType:     mimeType,
+			UploadedBy:   fileRecord.UploadedBy,
+			Purpose:      fileRecord.Purpose,
+			ParentUUID:   &fileRecord.UUID,
+			Variant:      &variant,
+			Width:        &width,
+			Height:       &height,
+		}
+
+		// Store file data
+		err = f.storage.Put(ctx, child.FilePath, bytes.NewReader(img.data), child.FileSize, mimeType)
+		if err != nil {
+			return stored, fmt.Errorf("failed to store file: %v", err)
+		}
+		stored = append(stored, child.FilePath)
+
+		fileRecord.Variants = append(fileRecord.Variants, child)
 	}
 
-	// Save to database
-	err = tx.QueryRow(CreateQuery,
-		fileRecord.UUID,
-		fileRecord.OriginalName,
-		fileRecord.FileName,
-		fileRecord.FilePath,
-		fileRecord.FileSize,
-		fileRecord.MimeType,
-		fileRecord.UploadedBy,
-	).Scan(&fileRecord.CreatedAt)
+	// Save to database, parent first
+	for _, record := range append([]*db.File{fileRecord}, fileRecord.Variants...) {
+		if err := f.create(ctx, tx, record); err != nil {
+			return stored, fmt.Errorf("failed to save file record: %v", err)
+		}
+	}
 
+	return stored, nil
+}
+
+// storeDocument streams a file to storage and returns its key. check, if
+// set, runs once the content has been read in full.
+func (f *File) storeDocument(ctx context.Context, tx *sqlx.Tx, fileRecord *db.File, r io.Reader, size int64, mimeType string, check func() error) ([]string, error) {
+	fileRecord.FileName = fileRecord.UUID + extensions[mimeType]
+	fileRecord.FilePath = "documents/" + fileRecord.FileName
+	fileRecord.FileSize = size
+	fileRecord.MimeType = mimeType
+
+	// Store file data
+	err := f.storage.Put(ctx, fileRecord.FilePath, r, fileRecord.FileSize, mimeType)
 	if err != nil {
-		// Clean up file if database insert fails
-		os.Remove(filePath)
-		return nil, fmt.Errorf("failed to save file record: %v", err)
+		// A failed Put may still have left a partial object
+		return []string{fileRecord.FilePath}, fmt.Errorf("failed to store file: %v", err)
+	}
+	stored := []string{fileRecord.FilePath}
+
+	if check != nil {
+		if err := check(); err != nil {
+			return stored, err
+		}
+	}
+
+	// Save to database
+	if err := f.create(ctx, tx, fileRecord); err != nil {
+		return stored, fmt.Errorf("failed to save file record: %v", err)
 	}
 
-	return fileRecord, nil
+	return stored, nil
 }
 
-func (f *File) GetByUUID(uuid string) (*db.File, error) {
+func (f *File) create(ctx context.Context, tx *sqlx.Tx, file *db.File) error {
+	return tx.QueryRowContext(ctx, CreateQuery,
+		file.UUID,
+		file.OriginalName,
+		file.FileName,
+		file.FilePath,
+		file.FileSize,
+		file.MimeType,
+		file.UploadedBy,
+		file.Purpose,
+		file.ParentUUID,
+		file.Variant,
+		file.Width,
+		file.Height,
+	).Scan(&file.CreatedAt)
+}
+
+func (f *File) GetByUUID(ctx context.Context, uuid string) (*db.File, error) {
 	file := &db.File{}
-	err := f.db.Get(file, GetByUUIDQuery, uuid)
-	return file, err
+	err := f.db.GetContext(ctx, file, GetByUUIDQuery, uuid)
+	return file, constant.FromDB(err, "file")
 }
 
-func (f *File) Delete(tx *sqlx.Tx, uuid string) error {
+// Delete removes a file's row and its variants' rows and returns the storage
+// keys they pointed at. Variants cannot be deleted on their own since the
+// original one shares the upload's object. The objects are left for
+// DeleteObjects once the transaction has committed, so a rollback never
+// loses a file's content.
+func (f *File) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) ([]string, error) {
 	// Get file info first
-	file, err := f.GetByUUID(uuid)
+	file, err := f.GetByUUID(ctx, uuid)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if file.ParentUUID != nil {
+		return nil, constant.ErrFileIsVariant
 	}
 
-	// Delete from database
-	_, err = tx.Exec(DeleteQuery, uuid)
+	variants, err := f.GetVariants(ctx, []string{uuid})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Delete physical file
-	err = os.Remove(file.FilePath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete physical file: %v", err)
+	// Delete from database; variant rows cascade
+	_, err = tx.ExecContext(ctx, DeleteQuery, uuid)
+	if err != nil {
+		return nil, constant.FromDB(err, "file")
 	}
 
-	return nil
+	keys := []string{file.FilePath}
+	for _, variant := range variants[uuid] {
+		if variant.FilePath != file.FilePath {
+			keys = append(keys, variant.FilePath)
+		}
+	}
+
+	return keys, nil
 }
 
-func (f *File) GetByUploadedBy(uploadedBy string) ([]*db.File, error) {
+// DeleteObjects removes stored objects, carrying on past failures so one
+// missing object does not leave the rest behind.
+func (f *File) DeleteObjects(ctx context.Context, keys []string) error {
+	var firstErr error
+	for _, key := range keys {
+		if err := f.storage.Delete(ctx, key); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to delete stored file: %v", err)
+		}
+	}
+	return firstErr
+}
+
+func (f *File) GetByUploadedBy(ctx context.Context, uploadedBy string) ([]*db.File, error) {
 	var files []*db.File
-	err := f.db.Select(&files, GetByUploadedByQuery, uploadedBy)
-	return files, err
+	err := f.db.SelectContext(ctx, &files, GetByUploadedByQuery, uploadedBy)
+	return files, constant.FromDB(err, "file")
 }
 
-func (f *File) isValidImageType(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	validExts := []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+// GetVariants loads the variants of many files in one query, grouped by the
+// UUID of the file they were generated from.
+func (f *File) GetVariants(ctx context.Context, fileUUIDs []string) (map[string][]*db.File, error) {
+	variants := make(map[string][]*db.File, len(fileUUIDs))
+	if len(fileUUIDs) == 0 {
+		return variants, nil
+	}
 
-	for _, validExt := range validExts {
-		if ext == validExt {
-			return true
-		}
+	var rows []*db.File
+	if err := f.db.SelectContext(ctx, &rows, GetVariantsQuery, pq.Array(fileUUIDs)); err != nil {
+		return nil, constant.FromDB(err, "file")
 	}
-	return false
+
+	for _, row := range rows {
+		variants[*row.ParentUUID] = append(variants[*row.ParentUUID], row)
+	}
+
+	return variants, nil
+}
+
+// URL returns the address clients download the file from. FilePath holds
+// the storage key.
+func (f *File) URL(ctx context.Context, file *db.File) (string, error) {
+	return f.storage.Presign(ctx, file.FilePath, f.urlExpiry)
+}
+
+func (f *File) AttachToStudent(ctx context.Context, tx *sqlx.Tx, studentUUID, fileUUID string) error {
+	_, err := tx.ExecContext(ctx, AttachToStudentQuery, studentUUID, fileUUID)
+	return constant.FromDB(err, "student file")
+}
+
+func (f *File) DetachFromStudent(ctx context.Context, tx *sqlx.Tx, studentUUID, fileUUID string) error {
+	_, err := tx.ExecContext(ctx, DetachFromStudentQuery, studentUUID, fileUUID)
+	return constant.FromDB(err, "student file")
+}
+
+func (f *File) GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.File, error) {
+	var files []*db.File
+	err := f.db.SelectContext(ctx, &files, GetByStudentUUIDQuery, studentUUID)
+	return files, constant.FromDB(err, "file")
+}
+
+func (f *File) AttachToProject(ctx context.Context, tx *sqlx.Tx, projectUUID, fileUUID string) error {
+	_, err := tx.ExecContext(ctx, AttachToProjectQuery, projectUUID, fileUUID)
+	return constant.FromDB(err, "project file")
+}
+
+func (f *File) DetachFromProject(ctx context.Context, tx *sqlx.Tx, projectUUID, fileUUID string) error {
+	_, err := tx.ExecContext(ctx, DetachFromProjectQuery, projectUUID, fileUUID)
+	return constant.FromDB(err, "project file")
+}
+
+func (f *File) GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.File, error) {
+	var files []*db.File
+	err := f.db.SelectContext(ctx, &files, GetByProjectUUIDQuery, projectUUID)
+	return files, constant.FromDB(err, "file")
 }
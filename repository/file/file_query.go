@@ -2,20 +2,49 @@ package file
 
 const (
 	CreateQuery = `
-		INSERT INTO files (uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO files (uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, purpose, parent_uuid, variant, width, height)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at
 	`
 
 	GetByUUIDQuery = `
-		SELECT uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, created_at
+		SELECT uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, purpose, parent_uuid, variant, width, height, created_at
 		FROM files WHERE uuid = $1
 	`
 
 	DeleteQuery = `DELETE FROM files WHERE uuid = $1`
 
 	GetByUploadedByQuery = `
-		SELECT uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, created_at
-		FROM files WHERE uploaded_by = $1 ORDER BY created_at DESC
+		SELECT uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, purpose, parent_uuid, variant, width, height, created_at
+		FROM files WHERE uploaded_by = $1 AND parent_uuid IS NULL ORDER BY created_at DESC
+	`
+
+	GetVariantsQuery = `
+		SELECT uuid, original_name, file_name, file_path, file_size, mime_type, uploaded_by, purpose, parent_uuid, variant, width, height, created_at
+		FROM files WHERE parent_uuid = ANY($1) ORDER BY width
+	`
+
+	AttachToStudentQuery = `INSERT INTO student_files (student_uuid, file_uuid) VALUES ($1, $2)`
+
+	DetachFromStudentQuery = `DELETE FROM student_files WHERE student_uuid = $1 AND file_uuid = $2`
+
+	GetByStudentUUIDQuery = `
+		SELECT f.uuid, f.original_name, f.file_name, f.file_path, f.file_size, f.mime_type, f.uploaded_by, f.purpose, f.parent_uuid, f.variant, f.width, f.height, f.created_at
+		FROM files f
+		JOIN student_files sf ON sf.file_uuid = f.uuid
+		WHERE sf.student_uuid = $1
+		ORDER BY sf.created_at DESC
+	`
+
+	AttachToProjectQuery = `INSERT INTO project_files (project_uuid, file_uuid) VALUES ($1, $2)`
+
+	DetachFromProjectQuery = `DELETE FROM project_files WHERE project_uuid = $1 AND file_uuid = $2`
+
+	GetByProjectUUIDQuery = `
+		SELECT f.uuid, f.original_name, f.file_name, f.file_path, f.file_size, f.mime_type, f.uploaded_by, f.purpose, f.parent_uuid, f.variant, f.width, f.height, f.created_at
+		FROM files f
+		JOIN project_files pf ON pf.file_uuid = f.uuid
+		WHERE pf.project_uuid = $1
+		ORDER BY pf.created_at DESC
 	`
 )
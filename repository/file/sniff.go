@@ -0,0 +1,101 @@
+package file
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	// Registered for image.DecodeConfig.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/HPNV/growlink-backend/constant"
+)
+
+const (
+	// sniffLen is how much of a file http.DetectContentType looks at.
+	sniffLen = 512
+	// Images beyond these dimensions are rejected before anything decodes
+	// them in full, so a small file cannot expand into gigabytes of pixels.
+	// At 24 megapixels one RGBA copy is about 96MB, and processing holds two.
+	maxImageSide   = 10000
+	maxImagePixels = 24_000_000
+	// pdfTrailerLen is how far from the end a PDF's %%EOF marker may sit.
+	pdfTrailerLen = 1024
+
+	pdfType = "application/pdf"
+)
+
+// extensions maps every MIME type the sniffer recognises to the extension
+// the stored file gets.
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	pdfType:           ".pdf",
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+	"application/zip": ".zip",
+}
+
+func isImage(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/") && extensions[mimeType] != ""
+}
+
+// detect identifies an upload from the magic bytes at its head rather than
+// its name or the client's Content-Type. Types outside allowed are rejected.
+func detect(head []byte, allowed []string) (string, error) {
+	mimeType := http.DetectContentType(head)
+	if len(head) == 0 || extensions[mimeType] == "" || !slices.Contains(allowed, mimeType) {
+		return "", constant.InvalidFileType(mimeType, allowed)
+	}
+	return mimeType, nil
+}
+
+// checkImage verifies that an image header decodes and is within the
+// dimension limits, and rewinds file for the caller.
+func checkImage(file io.ReadSeeker) error {
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return constant.ErrCorruptImage
+	}
+
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide || cfg.Width*cfg.Height > maxImagePixels {
+		return constant.ImageTooLarge(cfg.Width, cfg.Height, maxImageSide, maxImagePixels)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
+
+// checkPDF rejects truncated PDFs, which lack the %%EOF trailer. It is given
+// the end of the file, as kept by a trailer.
+func checkPDF(tail []byte) error {
+	tail = tail[max(0, len(tail)-pdfTrailerLen):]
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return constant.ErrCorruptDocument
+	}
+	return nil
+}
+
+// trailer is a writer that keeps only the last n bytes written to it, so a
+// streamed file's end can be checked without holding all of it.
+type trailer struct {
+	n   int
+	buf []byte
+}
+
+func (t *trailer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.n; over > 0 {
+		t.buf = t.buf[:copy(t.buf, t.buf[over:])]
+	}
+	return len(p), nil
+}
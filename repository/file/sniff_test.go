@@ -0,0 +1,107 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/HPNV/growlink-backend/constant"
+)
+
+func TestCheckPDFStreamed(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 1000)
+
+	tests := []struct {
+		name string
+		pdf  []byte
+		err  error
+	}{
+		{"complete", append(append([]byte("%PDF-1.7\n"), body...), "%%EOF\n"...), nil},
+		{"truncated", append([]byte("%PDF-1.7\n"), body...), constant.ErrCorruptDocument},
+		{"marker too far from the end", append(append([]byte("%PDF-1.7\n%%EOF\n"), body...), "\n"...), constant.ErrCorruptDocument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tail := &trailer{n: pdfTrailerLen}
+			// One byte at a time, so the marker can straddle writes
+			r := io.TeeReader(iotest.OneByteReader(bytes.NewReader(tt.pdf)), tail)
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tail.buf) != pdfTrailerLen {
+				t.Errorf("trailer kept %d bytes, want %d", len(tail.buf), pdfTrailerLen)
+			}
+			if err := checkPDF(tail.buf); !errors.Is(err, tt.err) {
+				t.Errorf("checkPDF() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// pngHeader returns the start of a PNG declaring the given dimensions, which
+// is all image.DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12], ihdr[13] = 8, 2 // 8-bit RGB
+
+	buf := bytes.NewBufferString("\x89PNG\r\n\x1a\n")
+	binary.Write(buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckImage(t *testing.T) {
+	// A blank image over the pixel limit compresses to well under a megabyte
+	bomb := encodePNG(t, image.NewGray(image.Rect(0, 0, 5000, 5000)))
+	if len(bomb) > 1<<20 {
+		t.Fatalf("bomb is %d bytes, want a small file", len(bomb))
+	}
+
+	tests := []struct {
+		name  string
+		image []byte
+		err   error
+	}{
+		{"small image", encodePNG(t, image.NewRGBA(image.Rect(0, 0, 16, 16))), nil},
+		{"at the side limit", pngHeader(maxImageSide, 1), nil},
+		{"side over the limit", pngHeader(maxImageSide+1, 1), constant.ErrImageTooLarge},
+		{"pixels over the limit", pngHeader(maxImageSide, maxImagePixels/maxImageSide+1), constant.ErrImageTooLarge},
+		{"decompression bomb", bomb, constant.ErrImageTooLarge},
+		{"zero width", pngHeader(0, 16), constant.ErrCorruptImage},
+		{"not an image", []byte("%PDF-1.7\n"), constant.ErrCorruptImage},
+		{"truncated header", pngHeader(16, 16)[:20], constant.ErrCorruptImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.image)
+			if err := checkImage(r); !errors.Is(err, tt.err) {
+				t.Fatalf("checkImage() = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && r.Len() != len(tt.image) {
+				t.Errorf("checkImage() left the reader at %d, want it rewound", len(tt.image)-r.Len())
+			}
+		})
+	}
+}
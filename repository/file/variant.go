@@ -0,0 +1,152 @@
+package file
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"golang.org/x/image/draw"
+)
+
+const (
+	VariantThumbnail = "thumbnail"
+	VariantMedium    = "medium"
+	VariantOriginal  = "original"
+)
+
+// encodedImage is one variant of an upload, ready to be stored.
+type encodedImage struct {
+	variant string
+	data    []byte
+	width   int
+	height  int
+}
+
+// processImage decodes an upload, turns it upright according to its EXIF
+// orientation and re-encodes it once per variant. Re-encoding is what strips
+// EXIF, GPS and any other metadata: the encoders write none. JPEGs stay
+// JPEG, as do opaque WebPs; everything else becomes PNG. It returns the
+// output MIME type and extension along with the variants, original first.
+func processImage(data []byte, sourceType string, cfg config.ImageConfig) (string, string, []*encodedImage, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", "", nil, constant.ErrCorruptImage
+	}
+
+	img := toRGBA(decoded)
+	if sourceType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	mimeType, ext := "image/png", ".png"
+	if sourceType == "image/jpeg" || (sourceType == "image/webp" && img.Opaque()) {
+		mimeType, ext = "image/jpeg", ".jpg"
+	}
+
+	sizes := []struct {
+		variant string
+		maxSide int
+	}{
+		{VariantOriginal, 0},
+		{VariantMedium, cfg.MediumSize},
+		{VariantThumbnail, cfg.ThumbnailSize},
+	}
+
+	var variants []*encodedImage
+	for _, size := range sizes {
+		resized := fit(img, size.maxSide)
+
+		var buf bytes.Buffer
+		if mimeType == "image/jpeg" {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: cfg.JPEGQuality})
+		} else {
+			err = png.Encode(&buf, resized)
+		}
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		variants = append(variants, &encodedImage{
+			variant: size.variant,
+			data:    buf.Bytes(),
+			width:   resized.Bounds().Dx(),
+			height:  resized.Bounds().Dy(),
+		})
+	}
+
+	return mimeType, ext, variants, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// fit scales img down so neither side exceeds maxSide, keeping the aspect
+// ratio. Images already small enough, and a maxSide of zero, leave it as is.
+func fit(img *image.RGBA, maxSide int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if maxSide <= 0 || (w <= maxSide && h <= maxSide) {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation so the pixels are stored upright once
+// the tag that described them is gone.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs a 90 degree clockwise turn
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs a 90 degree counter-clockwise turn
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,182 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/config"
+)
+
+var testImageConfig = config.ImageConfig{ThumbnailSize: 10, MediumSize: 30, JPEGQuality: 85}
+
+// gpsMarker is written into the GPS data of the test EXIF segment, so it
+// only shows up in an output that kept the metadata.
+const gpsMarker = "GPS-LOCATION-MARKER"
+
+// exifSegment builds an APP1 segment whose first IFD holds an orientation
+// tag and a pointer to a GPS IFD carrying gpsMarker.
+func exifSegment(orientation uint16) []byte {
+	order := binary.LittleEndian
+	tiff := []byte("II*\x00")
+	tiff = order.AppendUint32(tiff, 8)
+
+	// IFD0: orientation and the GPS IFD pointer
+	const gpsIFD = 8 + 2 + 2*12 + 4
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint16(tiff, exifOrientationTag)
+	tiff = order.AppendUint16(tiff, 3) // SHORT
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint32(tiff, uint32(orientation))
+	tiff = order.AppendUint16(tiff, 0x8825) // GPSInfo
+	tiff = order.AppendUint16(tiff, 4)      // LONG
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint32(tiff, gpsIFD)
+	tiff = order.AppendUint32(tiff, 0)
+
+	// GPS IFD: a processing method pointing at the marker
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x001B) // GPSProcessingMethod
+	tiff = order.AppendUint16(tiff, 7)      // UNDEFINED
+	tiff = order.AppendUint32(tiff, uint32(len(gpsMarker)))
+	tiff = order.AppendUint32(tiff, gpsIFD+2+12+4)
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, gpsMarker...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// testJPEG encodes a width x height image whose left half is red and right
+// half blue, with exif inserted right after the start of image marker.
+func testJPEG(t *testing.T, width, height int, exif []byte) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), exif...), data[2:]...)
+}
+
+func TestProcessImage(t *testing.T) {
+	type size struct{ width, height int }
+
+	tests := []struct {
+		name       string
+		data       []byte
+		sourceType string
+		mimeType   string
+		sizes      map[string]size
+	}{
+		{
+			name:       "png",
+			data:       encodePNG(t, image.NewRGBA(image.Rect(0, 0, 300, 100))),
+			sourceType: "image/png",
+			mimeType:   "image/png",
+			sizes:      map[string]size{VariantOriginal: {300, 100}, VariantMedium: {30, 10}, VariantThumbnail: {10, 3}},
+		},
+		{
+			name:       "smaller than every variant",
+			data:       encodePNG(t, image.NewRGBA(image.Rect(0, 0, 8, 6))),
+			sourceType: "image/png",
+			mimeType:   "image/png",
+			sizes:      map[string]size{VariantOriginal: {8, 6}, VariantMedium: {8, 6}, VariantThumbnail: {8, 6}},
+		},
+		{
+			name:       "jpeg without exif",
+			data:       testJPEG(t, 40, 20, nil),
+			sourceType: "image/jpeg",
+			mimeType:   "image/jpeg",
+			sizes:      map[string]size{VariantOriginal: {40, 20}, VariantMedium: {30, 15}, VariantThumbnail: {10, 5}},
+		},
+		{
+			name:       "jpeg with exif and gps",
+			data:       testJPEG(t, 40, 20, exifSegment(6)),
+			sourceType: "image/jpeg",
+			mimeType:   "image/jpeg",
+			// Orientation 6 turns the landscape image upright into portrait
+			sizes: map[string]size{VariantOriginal: {20, 40}, VariantMedium: {15, 30}, VariantThumbnail: {5, 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, _, variants, err := processImage(tt.data, tt.sourceType, testImageConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mimeType != tt.mimeType {
+				t.Errorf("mime type = %q, want %q", mimeType, tt.mimeType)
+			}
+			if len(variants) != len(tt.sizes) || variants[0].variant != VariantOriginal {
+				t.Fatalf("got %d variants starting with %q, want %d starting with the original", len(variants), variants[0].variant, len(tt.sizes))
+			}
+
+			for _, v := range variants {
+				want := tt.sizes[v.variant]
+				if v.width != want.width || v.height != want.height {
+					t.Errorf("%s is %dx%d, want %dx%d", v.variant, v.width, v.height, want.width, want.height)
+				}
+
+				decoded, _, err := image.DecodeConfig(bytes.NewReader(v.data))
+				if err != nil {
+					t.Fatalf("%s does not decode: %v", v.variant, err)
+				}
+				if decoded.Width != v.width || decoded.Height != v.height {
+					t.Errorf("%s encodes %dx%d, reported %dx%d", v.variant, decoded.Width, decoded.Height, v.width, v.height)
+				}
+
+				if bytes.Contains(v.data, []byte("Exif\x00\x00")) || bytes.Contains(v.data, []byte(gpsMarker)) {
+					t.Errorf("%s kept the EXIF metadata", v.variant)
+				}
+				if jpegOrientation(v.data) != 1 {
+					t.Errorf("%s still carries an orientation", v.variant)
+				}
+			}
+		})
+	}
+}
+
+// TestProcessImageOrientation checks that the pixels, not only the
+// dimensions, are turned: after a clockwise turn the red left half of the
+// source ends up on top.
+func TestProcessImageOrientation(t *testing.T) {
+	src := testJPEG(t, 40, 20, exifSegment(6))
+	if jpegOrientation(src) != 6 || !bytes.Contains(src, []byte(gpsMarker)) {
+		t.Fatal("test image does not carry the EXIF segment")
+	}
+
+	_, _, variants, err := processImage(src, "image/jpeg", testImageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(variants[0].data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top, _, _, _ := img.At(10, 5).RGBA()
+	bottom, _, _, _ := img.At(10, 35).RGBA()
+	if top < 0xC000 || bottom > 0x4000 {
+		t.Errorf("red is %#x at the top and %#x at the bottom, want the red half on top", top, bottom)
+	}
+}
@@ -1,28 +1,34 @@
 package project
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/jmoiron/sqlx"
 )
 
 type IProject interface {
-	Create(tx *sqlx.Tx, project *db.Project) error
-	GetByUUID(uuid string) (*db.Project, error)
-	Update(tx *sqlx.Tx, project *db.Project) error
-	Delete(tx *sqlx.Tx, uuid string) error
-	GetAll() ([]*db.Project, error)
-	GetAllList(queryParam *dto.ProjectListRequest) ([]*db.Project, int, error)
-	GetByBusinessUUID(businessUUID string) ([]*db.Project, error)
-	AddSkill(tx *sqlx.Tx, projectUUID, skillUUID string) error
-	RemoveSkill(tx *sqlx.Tx, projectUUID, skillUUID string) error
-	GetSkills(projectUUID string) ([]*db.Skill, error)
-	AddStudent(tx *sqlx.Tx, projectUUID, studentUUID string) error
-	RemoveStudent(tx *sqlx.Tx, projectUUID, studentUUID string) error
-	GetStudents(projectUUID string) ([]*db.Student, error)
+	Create(ctx context.Context, tx *sqlx.Tx, project *db.Project) error
+	GetByUUID(ctx context.Context, uuid string) (*db.Project, error)
+	Update(ctx context.Context, tx *sqlx.Tx, project *db.Project, previousStatus string) error
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error
+	GetAll(ctx context.Context) ([]*db.Project, error)
+	GetAllList(ctx context.Context, queryParam *dto.ProjectListRequest) ([]*db.Project, int, error)
+	GetByBusinessUUID(ctx context.Context, businessUUID string) ([]*db.Project, error)
+	AddSkill(ctx context.Context, tx *sqlx.Tx, projectUUID, skillUUID string) error
+	RemoveSkill(ctx context.Context, tx *sqlx.Tx, projectUUID, skillUUID string) error
+	GetSkills(ctx context.Context, projectUUID string) ([]*db.Skill, error)
+	AddStudent(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error
+	RemoveStudent(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error
+	GetStudents(ctx context.Context, projectUUID string) ([]*db.Student, error)
+	AddStatusHistory(ctx context.Context, tx *sqlx.Tx, history *db.ProjectStatusHistory) error
+	GetStatusHistory(ctx context.Context, projectUUID string) ([]*db.ProjectStatusHistory, error)
+	GetOpenBySharedSkills(ctx context.Context, studentUUID string, limit int) ([]*db.Project, error)
 }
 
 type Project struct {
@@ -35,76 +41,121 @@ func NewProject(db *sqlx.DB) IProject {
 	}
 }
 
-func (p *Project) Create(tx *sqlx.Tx, project *db.Project) error {
-	err := tx.QueryRow(CreateQuery, project.Name, project.Description, project.Duration, project.Timeline, project.Deliverables, project.CreatedBy).Scan(&project.UUID, &project.CreatedAt)
+func (p *Project) Create(ctx context.Context, tx *sqlx.Tx, project *db.Project) error {
+	err := tx.QueryRowContext(ctx, CreateQuery, project.Name, project.Description, project.Status, project.Duration, project.Timeline, project.Deliverables, project.CreatedBy).Scan(&project.UUID, &project.CreatedAt)
 	if err != nil {
-		return err
+		return constant.FromDB(err, "project")
 	}
 
 	return nil
 }
 
-func (p *Project) GetByUUID(uuid string) (*db.Project, error) {
+func (p *Project) GetByUUID(ctx context.Context, uuid string) (*db.Project, error) {
 	project := &db.Project{}
-	err := p.db.Get(project, GetByUUIDQuery, uuid)
-	return project, err
+	err := p.db.GetContext(ctx, project, GetByUUIDQuery, uuid)
+	return project, constant.FromDB(err, "project")
 }
 
-func (p *Project) Update(tx *sqlx.Tx, project *db.Project) error {
-	_, err := tx.Exec(UpdateQuery, project.Name, project.Description, project.Status, project.Duration, project.Timeline, project.Deliverables, project.UUID)
-	return err
+// Update saves a project, but only while its status is still previousStatus,
+// so two concurrent updates cannot both make a status transition that was
+// validated against the same old status. A project deleted in the meantime
+// is reported as not found.
+func (p *Project) Update(ctx context.Context, tx *sqlx.Tx, project *db.Project, previousStatus string) error {
+	result, err := tx.ExecContext(ctx, UpdateQuery, project.Name, project.Description, project.Status, project.Duration, project.Timeline, project.Deliverables, project.UUID, previousStatus)
+	if err != nil {
+		return constant.FromDB(err, "project")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, ExistsQuery, project.UUID); err != nil {
+			return constant.FromDB(err, "project")
+		}
+		if !exists {
+			return constant.FromDB(sql.ErrNoRows, "project")
+		}
+		return constant.ErrInvalidTransition
+	}
+
+	return nil
 }
 
-func (p *Project) Delete(tx *sqlx.Tx, uuid string) error {
-	_, err := tx.Exec(DeleteQuery, uuid)
-	return err
+func (p *Project) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error {
+	_, err := tx.ExecContext(ctx, DeleteQuery, uuid)
+	return constant.FromDB(err, "project")
 }
 
-func (p *Project) GetAll() ([]*db.Project, error) {
+func (p *Project) GetAll(ctx context.Context) ([]*db.Project, error) {
 	var projects []*db.Project
-	err := p.db.Select(&projects, GetAllQuery)
-	return projects, err
+	err := p.db.SelectContext(ctx, &projects, GetAllQuery)
+	return projects, constant.FromDB(err, "project")
 }
 
-func (p *Project) GetByBusinessUUID(businessUUID string) ([]*db.Project, error) {
+func (p *Project) GetByBusinessUUID(ctx context.Context, businessUUID string) ([]*db.Project, error) {
 	var projects []*db.Project
-	err := p.db.Select(&projects, GetByBusinessUUIDQuery, businessUUID)
-	return projects, err
+	err := p.db.SelectContext(ctx, &projects, GetByBusinessUUIDQuery, businessUUID)
+	return projects, constant.FromDB(err, "project")
 }
 
-func (p *Project) AddSkill(tx *sqlx.Tx, projectUUID, skillUUID string) error {
-	_, err := tx.Exec(AddSkillQuery, projectUUID, skillUUID)
-	return err
+func (p *Project) AddSkill(ctx context.Context, tx *sqlx.Tx, projectUUID, skillUUID string) error {
+	_, err := tx.ExecContext(ctx, AddSkillQuery, projectUUID, skillUUID)
+	return constant.FromDB(err, "project")
 }
 
-func (p *Project) RemoveSkill(tx *sqlx.Tx, projectUUID, skillUUID string) error {
-	_, err := tx.Exec(RemoveSkillQuery, projectUUID, skillUUID)
-	return err
+func (p *Project) RemoveSkill(ctx context.Context, tx *sqlx.Tx, projectUUID, skillUUID string) error {
+	_, err := tx.ExecContext(ctx, RemoveSkillQuery, projectUUID, skillUUID)
+	return constant.FromDB(err, "project")
 }
 
-func (p *Project) GetSkills(projectUUID string) ([]*db.Skill, error) {
+func (p *Project) GetSkills(ctx context.Context, projectUUID string) ([]*db.Skill, error) {
 	var skills []*db.Skill
-	err := p.db.Select(&skills, GetSkillsQuery, projectUUID)
-	return skills, err
+	err := p.db.SelectContext(ctx, &skills, GetSkillsQuery, projectUUID)
+	return skills, constant.FromDB(err, "project")
 }
 
-func (p *Project) AddStudent(tx *sqlx.Tx, projectUUID, studentUUID string) error {
-	_, err := tx.Exec(AddStudentQuery, studentUUID, projectUUID)
-	return err
+func (p *Project) AddStudent(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error {
+	_, err := tx.ExecContext(ctx, AddStudentQuery, studentUUID, projectUUID)
+	return constant.FromDB(err, "project")
 }
 
-func (p *Project) RemoveStudent(tx *sqlx.Tx, projectUUID, studentUUID string) error {
-	_, err := tx.Exec(RemoveStudentQuery, projectUUID, studentUUID)
-	return err
+func (p *Project) RemoveStudent(ctx context.Context, tx *sqlx.Tx, projectUUID, studentUUID string) error {
+	_, err := tx.ExecContext(ctx, RemoveStudentQuery, projectUUID, studentUUID)
+	return constant.FromDB(err, "project")
 }
 
-func (p *Project) GetStudents(projectUUID string) ([]*db.Student, error) {
+func (p *Project) GetStudents(ctx context.Context, projectUUID string) ([]*db.Student, error) {
 	var students []*db.Student
-	err := p.db.Select(&students, GetStudentsQuery, projectUUID)
-	return students, err
+	err := p.db.SelectContext(ctx, &students, GetStudentsQuery, projectUUID)
+	return students, constant.FromDB(err, "project")
+}
+
+func (p *Project) AddStatusHistory(ctx context.Context, tx *sqlx.Tx, history *db.ProjectStatusHistory) error {
+	err := tx.QueryRowContext(ctx, AddStatusHistoryQuery, history.ProjectUUID, history.FromStatus, history.ToStatus, history.ChangedBy).
+		Scan(&history.UUID, &history.ChangedAt)
+	return constant.FromDB(err, "project")
+}
+
+func (p *Project) GetStatusHistory(ctx context.Context, projectUUID string) ([]*db.ProjectStatusHistory, error) {
+	var history []*db.ProjectStatusHistory
+	err := p.db.SelectContext(ctx, &history, GetStatusHistoryQuery, projectUUID)
+	return history, constant.FromDB(err, "project")
 }
 
-func (p *Project) GetAllList(queryParam *dto.ProjectListRequest) ([]*db.Project, int, error) {
+// GetOpenBySharedSkills returns up to limit open projects that need at least
+// one of the student's skills and that the student is not already working
+// on. Projects the student covers the largest share of come first, newest
+// first among equals.
+func (p *Project) GetOpenBySharedSkills(ctx context.Context, studentUUID string, limit int) ([]*db.Project, error) {
+	var projects []*db.Project
+	err := p.db.SelectContext(ctx, &projects, GetOpenBySharedSkillsQuery, studentUUID, limit)
+	return projects, constant.FromDB(err, "project")
+}
+
+func (p *Project) GetAllList(ctx context.Context, queryParam *dto.ProjectListRequest) ([]*db.Project, int, error) {
 	var projects []*db.Project
 	var args []interface{}
 	var whereConditions []string
@@ -127,18 +178,19 @@ func (p *Project) GetAllList(queryParam *dto.ProjectListRequest) ([]*db.Project,
 		argIndex++
 	}
 
+	query := GetAllListQuery
 	if queryParam.Search != nil && *queryParam.Search != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf(`(
-			p.name ILIKE $%d OR 
-			p.description ILIKE $%d OR 
-			p.deliverables ILIKE $%d
-		)`, argIndex, argIndex, argIndex))
-		args = append(args, "%"+*queryParam.Search+"%")
+		whereConditions = append(whereConditions, fmt.Sprintf("p.search_vector @@ websearch_to_tsquery('english', $%d)", argIndex))
+		query = fmt.Sprintf(GetAllListSearchQuery, argIndex)
+		args = append(args, *queryParam.Search)
 		argIndex++
 	}
 
+	if queryParam.Cursor != nil {
+		return p.getAllListAfter(ctx, query, queryParam, whereConditions, args, argIndex)
+	}
+
 	// Build the complete query
-	query := GetAllListQuery
 	countQuery := GetAllListCountQuery
 
 	if len(whereConditions) > 0 {
@@ -149,13 +201,17 @@ func (p *Project) GetAllList(queryParam *dto.ProjectListRequest) ([]*db.Project,
 
 	// Get total count
 	var totalCount int
-	err := p.db.Get(&totalCount, countQuery, args...)
+	err := p.db.GetContext(ctx, &totalCount, countQuery, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, constant.FromDB(err, "project")
 	}
 
 	// Add ORDER BY and pagination
-	query += " ORDER BY p.created_at DESC"
+	if queryParam.Sort == dto.ProjectSortRelevance {
+		query += " ORDER BY rank DESC, p.created_at DESC, p.uuid DESC"
+	} else {
+		query += " ORDER BY p.created_at DESC, p.uuid DESC"
+	}
 
 	if queryParam.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argIndex)
@@ -170,10 +226,37 @@ func (p *Project) GetAllList(queryParam *dto.ProjectListRequest) ([]*db.Project,
 	}
 
 	// Execute the query
-	err = p.db.Select(&projects, query, args...)
+	err = p.db.SelectContext(ctx, &projects, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, constant.FromDB(err, "project")
 	}
 
 	return projects, totalCount, nil
 }
+
+// getAllListAfter is the keyset variant of GetAllList. It seeks past the
+// cursor on (created_at, uuid) instead of using OFFSET, skips the COUNT and
+// fetches one extra row so the caller can tell whether another page exists.
+func (p *Project) getAllListAfter(ctx context.Context, query string, queryParam *dto.ProjectListRequest, whereConditions []string, args []interface{}, argIndex int) ([]*db.Project, int, error) {
+	var projects []*db.Project
+
+	if queryParam.After != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("(p.created_at, p.uuid) < ($%d::timestamp, $%d::uuid)", argIndex, argIndex+1))
+		args = append(args, queryParam.After.Key, queryParam.After.UUID)
+		argIndex += 2
+	}
+
+	if len(whereConditions) > 0 {
+		query += " WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY p.created_at DESC, p.uuid DESC LIMIT $%d", argIndex)
+	args = append(args, queryParam.Limit+1)
+
+	err := p.db.SelectContext(ctx, &projects, query, args...)
+	if err != nil {
+		return nil, 0, constant.FromDB(err, "project")
+	}
+
+	return projects, 0, nil
+}
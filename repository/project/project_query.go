@@ -2,8 +2,8 @@ package project
 
 const (
 	CreateQuery = `
-		INSERT INTO projects (name, description, duration, timeline, deliverables, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO projects (name, description, status, duration, timeline, deliverables, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING uuid, created_at
 	`
 
@@ -23,9 +23,11 @@ const (
 	UpdateQuery = `
 		UPDATE projects 
 		SET name = $1, description = $2, status = $3, duration = $4, timeline = $5, deliverables = $6
-		WHERE uuid = $7
+		WHERE uuid = $7 AND status = $8
 	`
 
+	ExistsQuery = `SELECT EXISTS (SELECT 1 FROM projects WHERE uuid = $1)`
+
 	DeleteQuery = `DELETE FROM projects WHERE uuid = $1`
 
 	GetAllQuery = `SELECT uuid, name, description, status, duration, timeline, deliverables, created_by, created_at FROM projects ORDER BY created_at DESC`
@@ -69,8 +71,62 @@ const (
 		FROM projects p
 	`
 
+	// GetAllListSearchQuery is GetAllListQuery with relevance columns for a
+	// full-text search; %[1]d is the placeholder index of the search term.
+	// The snippet is HTML: the source text is escaped before the <mark> tags
+	// are added, so markup written by businesses is never passed through.
+	GetAllListSearchQuery = `
+		SELECT p.uuid, p.name, p.description, p.status, p.duration, p.timeline, p.deliverables, p.created_by, p.created_at,
+			ts_rank(p.search_vector, websearch_to_tsquery('english', $%[1]d)) AS rank,
+			ts_headline('english',
+				replace(replace(replace(replace(replace(
+					coalesce(p.description, '') || ' ' || coalesce(p.deliverables, ''),
+					'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+				websearch_to_tsquery('english', $%[1]d),
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS snippet
+		FROM projects p
+	`
+
 	GetAllListCountQuery = `
 		SELECT COUNT(*)
 		FROM projects p
 	`
+
+	AddStatusHistoryQuery = `
+		INSERT INTO project_status_history (project_uuid, from_status, to_status, changed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid, changed_at
+	`
+
+	GetStatusHistoryQuery = `
+		SELECT uuid, project_uuid, from_status, to_status, changed_by, changed_at
+		FROM project_status_history
+		WHERE project_uuid = $1
+		ORDER BY changed_at, uuid
+	`
+
+	// GetOpenBySharedSkillsQuery ranks candidates by the share of their
+	// skills student $1 has and returns the top $2.
+	GetOpenBySharedSkillsQuery = `
+		SELECT p.uuid, p.name, p.description, p.status, p.duration, p.timeline, p.deliverables, p.created_by, p.created_at
+		FROM projects p
+		JOIN (
+			SELECT ps.project_uuid, COUNT(ss.skill_uuid) AS shared, COUNT(*) AS total
+			FROM project_skills ps
+			LEFT JOIN student_skills ss ON ss.skill_uuid = ps.skill_uuid AND ss.student_uuid = $1
+			WHERE ps.project_uuid IN (
+				SELECT ps2.project_uuid FROM project_skills ps2
+				JOIN student_skills ss2 ON ss2.skill_uuid = ps2.skill_uuid
+				WHERE ss2.student_uuid = $1
+			)
+			GROUP BY ps.project_uuid
+		) m ON m.project_uuid = p.uuid
+		WHERE p.status = 'open'
+		AND NOT EXISTS (
+			SELECT 1 FROM student_projects sp
+			WHERE sp.project_uuid = p.uuid AND sp.student_uuid = $1
+		)
+		ORDER BY m.shared::float / m.total DESC, p.created_at DESC
+		LIMIT $2
+	`
 )
@@ -1,11 +1,17 @@
 package repository
 
 import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/repository/application"
 	"github.com/HPNV/growlink-backend/repository/business"
+	"github.com/HPNV/growlink-backend/repository/endorsement"
 	"github.com/HPNV/growlink-backend/repository/file"
 	"github.com/HPNV/growlink-backend/repository/project"
 	"github.com/HPNV/growlink-backend/repository/skill"
 	"github.com/HPNV/growlink-backend/repository/student"
+	"github.com/HPNV/growlink-backend/repository/token"
+	"github.com/HPNV/growlink-backend/repository/upload"
 	"github.com/HPNV/growlink-backend/repository/user"
 	"github.com/jmoiron/sqlx"
 )
@@ -18,17 +24,25 @@ type IRegistry interface {
 	GetStudent() student.IStudent
 	GetProject() project.IProject
 	GetFile() file.IFile
-	WithTransaction(txFunc func(tx *sqlx.Tx) error) error
+	GetToken() token.IToken
+	GetApplication() application.IApplication
+	GetEndorsement() endorsement.IEndorsement
+	GetUpload() upload.IUpload
+	WithTransaction(ctx context.Context, txFunc func(tx *sqlx.Tx) error) error
 }
 
 type Registry struct {
-	db       *sqlx.DB
-	user     user.IUser
-	skill    skill.ISkill
-	business business.IBusiness
-	student  student.IStudent
-	project  project.IProject
-	file     file.IFile
+	db          *sqlx.DB
+	user        user.IUser
+	skill       skill.ISkill
+	business    business.IBusiness
+	student     student.IStudent
+	project     project.IProject
+	file        file.IFile
+	token       token.IToken
+	application application.IApplication
+	endorsement endorsement.IEndorsement
+	upload      upload.IUpload
 }
 
 func NewRegistry(
@@ -39,15 +53,23 @@ func NewRegistry(
 	student student.IStudent,
 	project project.IProject,
 	file file.IFile,
+	token token.IToken,
+	application application.IApplication,
+	endorsement endorsement.IEndorsement,
+	upload upload.IUpload,
 ) *Registry {
 	return &Registry{
-		db:       db,
-		user:     user,
-		skill:    skill,
-		business: business,
-		student:  student,
-		project:  project,
-		file:     file,
+		db:          db,
+		user:        user,
+		skill:       skill,
+		business:    business,
+		student:     student,
+		project:     project,
+		file:        file,
+		token:       token,
+		application: application,
+		endorsement: endorsement,
+		upload:      upload,
 	}
 }
 
@@ -79,8 +101,26 @@ func (r *Registry) GetFile() file.IFile {
 	return r.file
 }
 
-func (r *Registry) WithTransaction(txFunc func(tx *sqlx.Tx) error) error {
-	tx, err := r.db.Beginx()
+func (r *Registry) GetToken() token.IToken {
+	return r.token
+}
+
+func (r *Registry) GetApplication() application.IApplication {
+	return r.application
+}
+
+func (r *Registry) GetEndorsement() endorsement.IEndorsement {
+	return r.endorsement
+}
+
+func (r *Registry) GetUpload() upload.IUpload {
+	return r.upload
+}
+
+// WithTransaction runs txFunc in a transaction bound to ctx, so a cancelled
+// request rolls the transaction back.
+func (r *Registry) WithTransaction(ctx context.Context, txFunc func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
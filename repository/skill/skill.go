@@ -2,21 +2,44 @@ package skill
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ISkill interface {
 	CreateSkill(ctx context.Context, tx *sqlx.Tx, name string) (string, error)
-	Create(tx *sqlx.Tx, skill *db.Skill) error
-	GetByUUID(uuid string) (*db.Skill, error)
-	GetByName(name string) (*db.Skill, error)
-	Update(tx *sqlx.Tx, skill *db.Skill) error
-	Delete(tx *sqlx.Tx, uuid string) error
-	GetAll() ([]*db.Skill, error)
-	GetByProjectUUID(projectUUID string) ([]*db.Skill, error)
-	GetByStudentUUID(studentUUID string) ([]*db.Skill, error)
+	Create(ctx context.Context, tx *sqlx.Tx, skill *db.Skill) error
+	GetByUUID(ctx context.Context, uuid string) (*db.Skill, error)
+	GetByName(ctx context.Context, name string) (*db.Skill, error)
+	Update(ctx context.Context, tx *sqlx.Tx, skill *db.Skill) error
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error
+	GetAll(ctx context.Context) ([]*db.Skill, error)
+	GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.Skill, error)
+	GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Skill, error)
+	GetByProjectUUIDs(ctx context.Context, projectUUIDs []string) (map[string][]*db.Skill, error)
+	GetByStudentUUIDs(ctx context.Context, studentUUIDs []string) (map[string][]*db.Skill, error)
+	GetStudentCounts(ctx context.Context) (map[string]int, error)
+	GetAliases(ctx context.Context, skillUUID string) ([]string, error)
+	AddAlias(ctx context.Context, tx *sqlx.Tx, skillUUID, alias string) error
+	RemoveAlias(ctx context.Context, tx *sqlx.Tx, skillUUID, alias string) error
+	Merge(ctx context.Context, tx *sqlx.Tx, sourceUUID, targetUUID string) error
+	CreateCategory(ctx context.Context, tx *sqlx.Tx, category *db.SkillCategory) error
+	GetCategoryByUUID(ctx context.Context, uuid string) (*db.SkillCategory, error)
+	GetCategories(ctx context.Context) ([]*db.SkillCategory, error)
+	Suggest(ctx context.Context, term string, limit int) ([]*db.SkillSuggestion, error)
+}
+
+// ownedSkill is a skill row tagged with the project or student it belongs to,
+// used by the batch lookups.
+type ownedSkill struct {
+	OwnerUUID string `db:"owner_uuid"`
+	db.Skill
 }
 
 type Skill struct {
@@ -29,55 +52,183 @@ func NewSkill(db *sqlx.DB) ISkill {
 	}
 }
 
+// normalizeName trims a skill name or alias and collapses inner whitespace,
+// so that "go " and "go" resolve to the same skill.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
 func (s *Skill) CreateSkill(ctx context.Context, tx *sqlx.Tx, name string) (string, error) {
 	var uuid string
-	err := tx.QueryRowContext(ctx, CreateSkillQuery, name).Scan(&uuid)
+	err := tx.QueryRowContext(ctx, CreateSkillQuery, normalizeName(name)).Scan(&uuid)
 	if err != nil {
-		return "", err
+		return "", constant.FromDB(err, "skill")
 	}
 	return uuid, nil
 }
 
-func (s *Skill) Create(tx *sqlx.Tx, skill *db.Skill) error {
-	return tx.QueryRow(CreateQuery, skill.Name, skill.Description).Scan(&skill.UUID, &skill.CreatedAt)
+func (s *Skill) Create(ctx context.Context, tx *sqlx.Tx, skill *db.Skill) error {
+	skill.Name = normalizeName(skill.Name)
+	err := tx.QueryRowContext(ctx, CreateQuery, skill.Name, skill.Description, skill.CategoryUUID).Scan(&skill.UUID, &skill.CreatedAt)
+	return constant.FromDB(err, "skill")
 }
 
-func (s *Skill) GetByUUID(uuid string) (*db.Skill, error) {
+func (s *Skill) GetByUUID(ctx context.Context, uuid string) (*db.Skill, error) {
 	skill := &db.Skill{}
-	err := s.db.Get(skill, GetByUUIDQuery, uuid)
-	return skill, err
+	err := s.db.GetContext(ctx, skill, GetByUUIDQuery, uuid)
+	return skill, constant.FromDB(err, "skill")
 }
 
-func (s *Skill) GetByName(name string) (*db.Skill, error) {
+// GetByName resolves a skill by its canonical name or one of its aliases,
+// ignoring case and surrounding whitespace.
+func (s *Skill) GetByName(ctx context.Context, name string) (*db.Skill, error) {
 	skill := &db.Skill{}
-	err := s.db.Get(skill, GetByNameQuery, name)
-	return skill, err
+	err := s.db.GetContext(ctx, skill, GetByNameQuery, normalizeName(name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return skill, constant.UnknownSkill(name)
+	}
+	return skill, constant.FromDB(err, "skill")
 }
 
-func (s *Skill) Update(tx *sqlx.Tx, skill *db.Skill) error {
-	_, err := tx.Exec(UpdateQuery, skill.Name, skill.Description, skill.UUID)
-	return err
+func (s *Skill) Update(ctx context.Context, tx *sqlx.Tx, skill *db.Skill) error {
+	skill.Name = normalizeName(skill.Name)
+	_, err := tx.ExecContext(ctx, UpdateQuery, skill.Name, skill.Description, skill.CategoryUUID, skill.UUID)
+	return constant.FromDB(err, "skill")
 }
 
-func (s *Skill) Delete(tx *sqlx.Tx, uuid string) error {
-	_, err := tx.Exec(DeleteQuery, uuid)
-	return err
+func (s *Skill) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error {
+	_, err := tx.ExecContext(ctx, DeleteQuery, uuid)
+	return constant.FromDB(err, "skill")
 }
 
-func (s *Skill) GetAll() ([]*db.Skill, error) {
+func (s *Skill) GetAll(ctx context.Context) ([]*db.Skill, error) {
 	var skills []*db.Skill
-	err := s.db.Select(&skills, GetAllQuery)
-	return skills, err
+	err := s.db.SelectContext(ctx, &skills, GetAllQuery)
+	return skills, constant.FromDB(err, "skill")
 }
 
-func (s *Skill) GetByProjectUUID(projectUUID string) ([]*db.Skill, error) {
+func (s *Skill) GetByProjectUUID(ctx context.Context, projectUUID string) ([]*db.Skill, error) {
 	var skills []*db.Skill
-	err := s.db.Select(&skills, GetByProjectUUIDQuery, projectUUID)
-	return skills, err
+	err := s.db.SelectContext(ctx, &skills, GetByProjectUUIDQuery, projectUUID)
+	return skills, constant.FromDB(err, "skill")
 }
 
-func (s *Skill) GetByStudentUUID(studentUUID string) ([]*db.Skill, error) {
+func (s *Skill) GetByStudentUUID(ctx context.Context, studentUUID string) ([]*db.Skill, error) {
 	var skills []*db.Skill
-	err := s.db.Select(&skills, GetByStudentUUIDQuery, studentUUID)
-	return skills, err
+	err := s.db.SelectContext(ctx, &skills, GetByStudentUUIDQuery, studentUUID)
+	return skills, constant.FromDB(err, "skill")
+}
+
+func (s *Skill) GetByProjectUUIDs(ctx context.Context, projectUUIDs []string) (map[string][]*db.Skill, error) {
+	return s.getByOwners(ctx, GetByProjectUUIDsQuery, projectUUIDs)
+}
+
+func (s *Skill) GetByStudentUUIDs(ctx context.Context, studentUUIDs []string) (map[string][]*db.Skill, error) {
+	return s.getByOwners(ctx, GetByStudentUUIDsQuery, studentUUIDs)
+}
+
+// GetStudentCounts returns how many students list each skill, keyed by skill
+// UUID. Skills nobody has are absent from the map.
+func (s *Skill) GetStudentCounts(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		SkillUUID string `db:"skill_uuid"`
+		Count     int    `db:"count"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, GetStudentCountsQuery); err != nil {
+		return nil, constant.FromDB(err, "skill")
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.SkillUUID] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (s *Skill) GetAliases(ctx context.Context, skillUUID string) ([]string, error) {
+	var aliases []string
+	err := s.db.SelectContext(ctx, &aliases, GetAliasesQuery, skillUUID)
+	return aliases, constant.FromDB(err, "skill alias")
+}
+
+func (s *Skill) AddAlias(ctx context.Context, tx *sqlx.Tx, skillUUID, alias string) error {
+	_, err := tx.ExecContext(ctx, AddAliasQuery, skillUUID, normalizeName(alias))
+	return constant.FromDB(err, "skill alias")
+}
+
+func (s *Skill) RemoveAlias(ctx context.Context, tx *sqlx.Tx, skillUUID, alias string) error {
+	_, err := tx.ExecContext(ctx, RemoveAliasQuery, skillUUID, normalizeName(alias))
+	return constant.FromDB(err, "skill alias")
+}
+
+// Merge re-points every student skill, endorsement, project skill and alias
+// from the source skill to the target and then deletes the source.
+func (s *Skill) Merge(ctx context.Context, tx *sqlx.Tx, sourceUUID, targetUUID string) error {
+	queries := []string{
+		MergeStudentSkillsQuery,
+		DropDuplicateEndorsementsQuery,
+		MergeEndorsementsQuery,
+		MergeProjectSkillsQuery,
+		MergeAliasesQuery,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, sourceUUID, targetUUID); err != nil {
+			return constant.FromDB(err, "skill")
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, DeleteQuery, sourceUUID)
+	return constant.FromDB(err, "skill")
+}
+
+func (s *Skill) CreateCategory(ctx context.Context, tx *sqlx.Tx, category *db.SkillCategory) error {
+	category.Name = normalizeName(category.Name)
+	err := tx.QueryRowContext(ctx, CreateCategoryQuery, category.Name, category.ParentUUID).Scan(&category.UUID, &category.CreatedAt)
+	return constant.FromDB(err, "skill category")
+}
+
+func (s *Skill) GetCategoryByUUID(ctx context.Context, uuid string) (*db.SkillCategory, error) {
+	category := &db.SkillCategory{}
+	err := s.db.GetContext(ctx, category, GetCategoryByUUIDQuery, uuid)
+	return category, constant.FromDB(err, "skill category")
+}
+
+func (s *Skill) GetCategories(ctx context.Context) ([]*db.SkillCategory, error) {
+	var categories []*db.SkillCategory
+	err := s.db.SelectContext(ctx, &categories, GetCategoriesQuery)
+	return categories, constant.FromDB(err, "skill category")
+}
+
+// likeEscaper escapes LIKE wildcards so user input only ever matches
+// literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *Skill) Suggest(ctx context.Context, term string, limit int) ([]*db.SkillSuggestion, error) {
+	term = strings.ToLower(normalizeName(term))
+
+	var suggestions []*db.SkillSuggestion
+	err := s.db.SelectContext(ctx, &suggestions, SuggestQuery, term, likeEscaper.Replace(term)+"%", limit)
+	return suggestions, constant.FromDB(err, "skill")
+}
+
+// getByOwners loads the skills of many owners in a single query and groups
+// them by owner UUID.
+func (s *Skill) getByOwners(ctx context.Context, query string, ownerUUIDs []string) (map[string][]*db.Skill, error) {
+	skills := make(map[string][]*db.Skill, len(ownerUUIDs))
+	if len(ownerUUIDs) == 0 {
+		return skills, nil
+	}
+
+	var rows []ownedSkill
+	if err := s.db.SelectContext(ctx, &rows, query, pq.Array(ownerUUIDs)); err != nil {
+		return nil, constant.FromDB(err, "skill")
+	}
+
+	for i := range rows {
+		skills[rows[i].OwnerUUID] = append(skills[rows[i].OwnerUUID], &rows[i].Skill)
+	}
+
+	return skills, nil
 }
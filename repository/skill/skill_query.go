@@ -4,24 +4,39 @@ const (
 	CreateSkillQuery = `INSERT INTO skills (name) VALUES ($1) RETURNING uuid`
 
 	CreateQuery = `
-		INSERT INTO skills (name, description)
-		VALUES ($1, $2)
+		INSERT INTO skills (name, description, category_uuid)
+		VALUES ($1, $2, $3)
 		RETURNING uuid, created_at
 	`
 
-	GetByUUIDQuery = `SELECT uuid, name, description, created_at FROM skills WHERE uuid = $1`
+	GetByUUIDQuery = `SELECT uuid, name, description, category_uuid, created_at FROM skills WHERE uuid = $1`
 
-	GetByNameQuery = `SELECT uuid, name, description, created_at FROM skills WHERE name = $1`
+	// GetByNameQuery matches the canonical name first and falls back to an
+	// alias, both case-insensitively.
+	GetByNameQuery = `
+		SELECT uuid, name, description, category_uuid, created_at FROM (
+			SELECT s.uuid, s.name, s.description, s.category_uuid, s.created_at, 0 AS priority
+			FROM skills s
+			WHERE lower(s.name) = lower($1)
+			UNION ALL
+			SELECT s.uuid, s.name, s.description, s.category_uuid, s.created_at, 1 AS priority
+			FROM skill_aliases a
+			JOIN skills s ON s.uuid = a.skill_uuid
+			WHERE lower(a.alias) = lower($1)
+		) matches
+		ORDER BY priority
+		LIMIT 1
+	`
 
 	UpdateQuery = `
 		UPDATE skills 
-		SET name = $1, description = $2
-		WHERE uuid = $3
+		SET name = $1, description = $2, category_uuid = $3
+		WHERE uuid = $4
 	`
 
 	DeleteQuery = `DELETE FROM skills WHERE uuid = $1`
 
-	GetAllQuery = `SELECT uuid, name, description, created_at FROM skills ORDER BY name`
+	GetAllQuery = `SELECT uuid, name, description, category_uuid, created_at FROM skills ORDER BY name`
 
 	GetByProjectUUIDQuery = `
 		SELECT s.uuid, s.name, s.description, s.created_at
@@ -36,4 +51,107 @@ const (
 		JOIN student_skills ss ON s.uuid = ss.skill_uuid
 		WHERE ss.student_uuid = $1
 	`
+
+	GetByProjectUUIDsQuery = `
+		SELECT ps.project_uuid AS owner_uuid, s.uuid, s.name, s.description, s.created_at
+		FROM skills s
+		JOIN project_skills ps ON s.uuid = ps.skill_uuid
+		WHERE ps.project_uuid = ANY($1)
+		ORDER BY s.name
+	`
+
+	GetByStudentUUIDsQuery = `
+		SELECT ss.student_uuid AS owner_uuid, s.uuid, s.name, s.description, s.created_at
+		FROM skills s
+		JOIN student_skills ss ON s.uuid = ss.skill_uuid
+		WHERE ss.student_uuid = ANY($1)
+		ORDER BY s.name
+	`
+
+	GetStudentCountsQuery = `
+		SELECT skill_uuid, COUNT(*) AS count
+		FROM student_skills
+		GROUP BY skill_uuid
+	`
+
+	GetAliasesQuery = `SELECT alias FROM skill_aliases WHERE skill_uuid = $1 ORDER BY alias`
+
+	AddAliasQuery = `INSERT INTO skill_aliases (skill_uuid, alias) VALUES ($1, $2)`
+
+	RemoveAliasQuery = `DELETE FROM skill_aliases WHERE skill_uuid = $1 AND lower(alias) = lower($2)`
+
+	CreateCategoryQuery = `
+		INSERT INTO skill_categories (name, parent_uuid)
+		VALUES ($1, $2)
+		RETURNING uuid, created_at
+	`
+
+	GetCategoryByUUIDQuery = `SELECT uuid, name, parent_uuid, created_at FROM skill_categories WHERE uuid = $1`
+
+	GetCategoriesQuery = `SELECT uuid, name, parent_uuid, created_at FROM skill_categories ORDER BY name`
+
+	// The merge queries move everything attached to skill $1 onto skill $2.
+	// A student who has both keeps the higher proficiency and experience.
+	MergeStudentSkillsQuery = `
+		INSERT INTO student_skills (student_uuid, skill_uuid, proficiency, years_experience)
+		SELECT student_uuid, $2, proficiency, years_experience
+		FROM student_skills WHERE skill_uuid = $1
+		ON CONFLICT (student_uuid, skill_uuid) DO UPDATE
+		SET proficiency = CASE
+				WHEN array_position(ARRAY['beginner', 'intermediate', 'advanced', 'expert']::varchar[], EXCLUDED.proficiency)
+					> array_position(ARRAY['beginner', 'intermediate', 'advanced', 'expert']::varchar[], student_skills.proficiency)
+				THEN EXCLUDED.proficiency
+				ELSE student_skills.proficiency
+			END,
+			years_experience = GREATEST(student_skills.years_experience, EXCLUDED.years_experience)
+	`
+
+	DropDuplicateEndorsementsQuery = `
+		DELETE FROM skill_endorsements se
+		WHERE se.skill_uuid = $1 AND EXISTS (
+			SELECT 1 FROM skill_endorsements t
+			WHERE t.skill_uuid = $2 AND t.student_uuid = se.student_uuid AND t.project_uuid = se.project_uuid
+		)
+	`
+
+	MergeEndorsementsQuery = `UPDATE skill_endorsements SET skill_uuid = $2 WHERE skill_uuid = $1`
+
+	MergeProjectSkillsQuery = `
+		INSERT INTO project_skills (project_uuid, skill_uuid)
+		SELECT project_uuid, $2 FROM project_skills WHERE skill_uuid = $1
+		ON CONFLICT (project_uuid, skill_uuid) DO NOTHING
+	`
+
+	MergeAliasesQuery = `UPDATE skill_aliases SET skill_uuid = $2 WHERE skill_uuid = $1`
+
+	// SuggestQuery ranks skills whose name or alias starts with or closely
+	// resembles the lowercased term $1. $2 is the escaped LIKE prefix pattern
+	// and $3 the limit. Prefix hits come first, then trigram similarity, then
+	// how often the skill is used.
+	SuggestQuery = `
+		WITH matches AS (
+			SELECT s.uuid AS skill_uuid, NULL::text AS matched_alias,
+				lower(s.name) LIKE $2 AS prefix,
+				similarity(lower(s.name), $1) AS score
+			FROM skills s
+			WHERE lower(s.name) LIKE $2 OR lower(s.name) % $1
+			UNION ALL
+			SELECT a.skill_uuid, a.alias,
+				lower(a.alias) LIKE $2,
+				similarity(lower(a.alias), $1)
+			FROM skill_aliases a
+			WHERE lower(a.alias) LIKE $2 OR lower(a.alias) % $1
+		), best AS (
+			SELECT DISTINCT ON (skill_uuid) skill_uuid, matched_alias, prefix, score
+			FROM matches
+			ORDER BY skill_uuid, prefix DESC, score DESC
+		)
+		SELECT s.uuid, s.name, b.matched_alias, b.score,
+			(SELECT COUNT(*) FROM project_skills ps WHERE ps.skill_uuid = s.uuid) AS project_count,
+			(SELECT COUNT(*) FROM student_skills ss WHERE ss.skill_uuid = s.uuid) AS student_count
+		FROM best b
+		JOIN skills s ON s.uuid = b.skill_uuid
+		ORDER BY b.prefix DESC, b.score DESC, project_count + student_count DESC, s.name
+		LIMIT $3
+	`
 )
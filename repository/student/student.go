@@ -1,20 +1,27 @@
 package student
 
 import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type IStudent interface {
-	Create(tx *sqlx.Tx, student *db.Student) error
-	GetByUUID(uuid string) (*db.Student, error)
-	GetByUserUUID(userUUID string) (*db.Student, error)
-	Update(tx *sqlx.Tx, student *db.Student) error
-	Delete(tx *sqlx.Tx, uuid string) error
-	GetAll() ([]*db.Student, error)
-	AddSkill(tx *sqlx.Tx, studentUUID, skillUUID string) error
-	RemoveSkill(tx *sqlx.Tx, studentUUID, skillUUID string) error
-	GetSkills(studentUUID string) ([]*db.Skill, error)
+	Create(ctx context.Context, tx *sqlx.Tx, student *db.Student) error
+	GetByUUID(ctx context.Context, uuid string) (*db.Student, error)
+	GetByUserUUID(ctx context.Context, userUUID string) (*db.Student, error)
+	Update(ctx context.Context, tx *sqlx.Tx, student *db.Student) error
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error
+	GetAll(ctx context.Context) ([]*db.Student, error)
+	AddSkill(ctx context.Context, tx *sqlx.Tx, studentUUID, skillUUID string, proficiency *string, yearsExperience *int) error
+	RemoveSkill(ctx context.Context, tx *sqlx.Tx, studentUUID, skillUUID string) error
+	GetSkills(ctx context.Context, studentUUID string) ([]*db.StudentSkill, error)
+	GetSkillsByStudentUUIDs(ctx context.Context, studentUUIDs []string) (map[string][]*db.StudentSkill, error)
+	Count(ctx context.Context) (int, error)
+	GetBySharedSkills(ctx context.Context, projectUUID string, limit int) ([]*db.Student, error)
 }
 
 type Student struct {
@@ -27,50 +34,89 @@ func NewStudent(db *sqlx.DB) IStudent {
 	}
 }
 
-func (s *Student) Create(tx *sqlx.Tx, student *db.Student) error {
-	return tx.QueryRow(CreateQuery, student.UserUUID, student.University).Scan(&student.UUID)
+func (s *Student) Create(ctx context.Context, tx *sqlx.Tx, student *db.Student) error {
+	err := tx.QueryRowContext(ctx, CreateQuery, student.UserUUID, student.University).Scan(&student.UUID)
+	return constant.FromDB(err, "student")
 }
 
-func (s *Student) GetByUUID(uuid string) (*db.Student, error) {
+func (s *Student) GetByUUID(ctx context.Context, uuid string) (*db.Student, error) {
 	student := &db.Student{}
-	err := s.db.Get(student, GetByUUIDQuery, uuid)
-	return student, err
+	err := s.db.GetContext(ctx, student, GetByUUIDQuery, uuid)
+	return student, constant.FromDB(err, "student")
 }
 
-func (s *Student) GetByUserUUID(userUUID string) (*db.Student, error) {
+func (s *Student) GetByUserUUID(ctx context.Context, userUUID string) (*db.Student, error) {
 	student := &db.Student{}
-	err := s.db.Get(student, GetByUserUUIDQuery, userUUID)
-	return student, err
+	err := s.db.GetContext(ctx, student, GetByUserUUIDQuery, userUUID)
+	return student, constant.FromDB(err, "student")
 }
 
-func (s *Student) Update(tx *sqlx.Tx, student *db.Student) error {
-	_, err := tx.Exec(UpdateQuery, student.University, student.UUID)
-	return err
+func (s *Student) Update(ctx context.Context, tx *sqlx.Tx, student *db.Student) error {
+	_, err := tx.ExecContext(ctx, UpdateQuery, student.University, student.UUID)
+	return constant.FromDB(err, "student")
 }
 
-func (s *Student) Delete(tx *sqlx.Tx, uuid string) error {
-	_, err := tx.Exec(DeleteQuery, uuid)
-	return err
+func (s *Student) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error {
+	_, err := tx.ExecContext(ctx, DeleteQuery, uuid)
+	return constant.FromDB(err, "student")
 }
 
-func (s *Student) GetAll() ([]*db.Student, error) {
+func (s *Student) GetAll(ctx context.Context) ([]*db.Student, error) {
 	var students []*db.Student
-	err := s.db.Select(&students, GetAllQuery)
-	return students, err
+	err := s.db.SelectContext(ctx, &students, GetAllQuery)
+	return students, constant.FromDB(err, "student")
+}
+
+// AddSkill adds the skill to the student, or updates the proficiency and
+// experience when the student already has it. A nil value keeps the current
+// one, or takes the column default for a new skill.
+func (s *Student) AddSkill(ctx context.Context, tx *sqlx.Tx, studentUUID, skillUUID string, proficiency *string, yearsExperience *int) error {
+	_, err := tx.ExecContext(ctx, AddSkillQuery, studentUUID, skillUUID, proficiency, yearsExperience)
+	return constant.FromDB(err, "student")
+}
+
+func (s *Student) RemoveSkill(ctx context.Context, tx *sqlx.Tx, studentUUID, skillUUID string) error {
+	_, err := tx.ExecContext(ctx, RemoveSkillQuery, studentUUID, skillUUID)
+	return constant.FromDB(err, "student")
+}
+
+func (s *Student) GetSkills(ctx context.Context, studentUUID string) ([]*db.StudentSkill, error) {
+	var skills []*db.StudentSkill
+	err := s.db.SelectContext(ctx, &skills, GetSkillsQuery, studentUUID)
+	return skills, constant.FromDB(err, "student")
 }
 
-func (s *Student) AddSkill(tx *sqlx.Tx, studentUUID, skillUUID string) error {
-	_, err := tx.Exec(AddSkillQuery, studentUUID, skillUUID)
-	return err
+// GetSkillsByStudentUUIDs is the batch form of GetSkills, grouped by student
+// UUID.
+func (s *Student) GetSkillsByStudentUUIDs(ctx context.Context, studentUUIDs []string) (map[string][]*db.StudentSkill, error) {
+	skills := make(map[string][]*db.StudentSkill, len(studentUUIDs))
+	if len(studentUUIDs) == 0 {
+		return skills, nil
+	}
+
+	var rows []*db.StudentSkill
+	if err := s.db.SelectContext(ctx, &rows, GetSkillsByStudentUUIDsQuery, pq.Array(studentUUIDs)); err != nil {
+		return nil, constant.FromDB(err, "student")
+	}
+
+	for _, row := range rows {
+		skills[row.StudentUUID] = append(skills[row.StudentUUID], row)
+	}
+
+	return skills, nil
 }
 
-func (s *Student) RemoveSkill(tx *sqlx.Tx, studentUUID, skillUUID string) error {
-	_, err := tx.Exec(RemoveSkillQuery, studentUUID, skillUUID)
-	return err
+func (s *Student) Count(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.GetContext(ctx, &count, CountQuery)
+	return count, constant.FromDB(err, "student")
 }
 
-func (s *Student) GetSkills(studentUUID string) ([]*db.Skill, error) {
-	var skills []*db.Skill
-	err := s.db.Select(&skills, GetSkillsQuery, studentUUID)
-	return skills, err
+// GetBySharedSkills returns up to limit students who have at least one of
+// the project's skills and are not already on the project, those with the
+// most of them first.
+func (s *Student) GetBySharedSkills(ctx context.Context, projectUUID string, limit int) ([]*db.Student, error) {
+	var students []*db.Student
+	err := s.db.SelectContext(ctx, &students, GetBySharedSkillsQuery, projectUUID, limit)
+	return students, constant.FromDB(err, "student")
 }
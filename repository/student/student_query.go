@@ -22,18 +22,58 @@ const (
 	GetAllQuery = `SELECT uuid, user_uuid, university FROM students ORDER BY university`
 
 	AddSkillQuery = `
-		INSERT INTO student_skills (student_uuid, skill_uuid)
-		VALUES ($1, $2)
-		ON CONFLICT (student_uuid, skill_uuid) DO NOTHING
+		INSERT INTO student_skills (student_uuid, skill_uuid, proficiency, years_experience)
+		VALUES ($1, $2, COALESCE($3::varchar, 'beginner'), COALESCE($4::int, 0))
+		ON CONFLICT (student_uuid, skill_uuid) DO UPDATE
+		SET proficiency = COALESCE($3::varchar, student_skills.proficiency),
+			years_experience = COALESCE($4::int, student_skills.years_experience)
 	`
 
 	RemoveSkillQuery = `DELETE FROM student_skills WHERE student_uuid = $1 AND skill_uuid = $2`
 
-	GetSkillsQuery = `
-		SELECT s.uuid, s.name, s.description, s.created_at
-		FROM skills s
-		INNER JOIN student_skills ss ON s.uuid = ss.skill_uuid
+	GetSkillsQuery = studentSkillSelect + `
 		WHERE ss.student_uuid = $1
 		ORDER BY s.name
 	`
+
+	GetSkillsByStudentUUIDsQuery = studentSkillSelect + `
+		WHERE ss.student_uuid = ANY($1)
+		ORDER BY s.name
+	`
+
+	studentSkillSelect = `
+		SELECT
+			ss.student_uuid,
+			ss.skill_uuid,
+			s.name AS skill_name,
+			COALESCE(s.description, '') AS skill_description,
+			ss.proficiency,
+			ss.years_experience,
+			(
+				SELECT COUNT(*) FROM skill_endorsements se
+				WHERE se.student_uuid = ss.student_uuid AND se.skill_uuid = ss.skill_uuid
+			) AS endorsement_count
+		FROM student_skills ss
+		INNER JOIN skills s ON s.uuid = ss.skill_uuid
+	`
+
+	CountQuery = `SELECT COUNT(*) FROM students`
+
+	GetBySharedSkillsQuery = `
+		SELECT st.uuid, st.user_uuid, st.university
+		FROM students st
+		JOIN (
+			SELECT ss.student_uuid, COUNT(*) AS shared
+			FROM student_skills ss
+			JOIN project_skills ps ON ps.skill_uuid = ss.skill_uuid
+			WHERE ps.project_uuid = $1
+			GROUP BY ss.student_uuid
+		) m ON m.student_uuid = st.uuid
+		WHERE NOT EXISTS (
+			SELECT 1 FROM student_projects sp
+			WHERE sp.student_uuid = st.uuid AND sp.project_uuid = $1
+		)
+		ORDER BY m.shared DESC, st.uuid
+		LIMIT $2
+	`
 )
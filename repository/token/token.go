@@ -0,0 +1,63 @@
+package token
+
+import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type IToken interface {
+	Create(ctx context.Context, tx *sqlx.Tx, token *db.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*db.RefreshToken, error)
+	Revoke(ctx context.Context, tx *sqlx.Tx, uuid string, replacedBy *string) error
+	RevokeAllByUser(ctx context.Context, tx *sqlx.Tx, userUUID string) error
+}
+
+type Token struct {
+	db *sqlx.DB
+}
+
+func NewToken(db *sqlx.DB) IToken {
+	return &Token{
+		db: db,
+	}
+}
+
+func (t *Token) Create(ctx context.Context, tx *sqlx.Tx, token *db.RefreshToken) error {
+	err := tx.QueryRowContext(ctx, CreateQuery, token.UserUUID, token.TokenHash, token.ExpiresAt).
+		Scan(&token.UUID, &token.CreatedAt)
+	return constant.FromDB(err, "refresh token")
+}
+
+func (t *Token) GetByHash(ctx context.Context, tokenHash string) (*db.RefreshToken, error) {
+	token := &db.RefreshToken{}
+	err := t.db.GetContext(ctx, token, GetByHashQuery, tokenHash)
+	return token, constant.FromDB(err, "refresh token")
+}
+
+// Revoke marks a token revoked. It fails with ErrInvalidRefreshToken when the
+// token was already revoked, so only one of two concurrent rotations of the
+// same token can succeed.
+func (t *Token) Revoke(ctx context.Context, tx *sqlx.Tx, uuid string, replacedBy *string) error {
+	result, err := tx.ExecContext(ctx, RevokeQuery, uuid, replacedBy)
+	if err != nil {
+		return constant.FromDB(err, "refresh token")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return constant.ErrInvalidRefreshToken
+	}
+
+	return nil
+}
+
+func (t *Token) RevokeAllByUser(ctx context.Context, tx *sqlx.Tx, userUUID string) error {
+	_, err := tx.ExecContext(ctx, RevokeAllByUserQuery, userUUID)
+	return constant.FromDB(err, "refresh token")
+}
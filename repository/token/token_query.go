@@ -0,0 +1,26 @@
+package token
+
+const (
+	CreateQuery = `
+		INSERT INTO refresh_tokens (user_uuid, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING uuid, created_at
+	`
+
+	GetByHashQuery = `
+		SELECT uuid, user_uuid, token_hash, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens WHERE token_hash = $1
+	`
+
+	RevokeQuery = `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP, replaced_by = $2
+		WHERE uuid = $1 AND revoked_at IS NULL
+	`
+
+	RevokeAllByUserQuery = `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_uuid = $1 AND revoked_at IS NULL
+	`
+)
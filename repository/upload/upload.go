@@ -0,0 +1,181 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/storage"
+	"github.com/jmoiron/sqlx"
+)
+
+type IUpload interface {
+	Create(ctx context.Context, tx *sqlx.Tx, upload *db.FileUpload) error
+	GetByUUID(ctx context.Context, uuid string) (*db.FileUpload, error)
+	Advance(ctx context.Context, tx *sqlx.Tx, uuid string, from, to int64, chunkKey string) error
+	Complete(ctx context.Context, tx *sqlx.Tx, uuid, fileUUID string) error
+	Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error
+	DeleteStale(ctx context.Context, tx *sqlx.Tx, age time.Duration, limit int) ([]*db.FileUpload, error)
+	PutChunk(ctx context.Context, uuid string, offset int64, r io.Reader, size int64) (string, error)
+	Open(ctx context.Context, upload *db.FileUpload) io.ReadCloser
+	DeleteChunks(ctx context.Context, keys []string) error
+}
+
+type Upload struct {
+	db      *sqlx.DB
+	storage storage.IStorage
+}
+
+func NewUpload(db *sqlx.DB, storage storage.IStorage) IUpload {
+	return &Upload{
+		db:      db,
+		storage: storage,
+	}
+}
+
+func (u *Upload) Create(ctx context.Context, tx *sqlx.Tx, upload *db.FileUpload) error {
+	err := tx.QueryRowContext(ctx, CreateQuery, upload.UploadedBy, upload.Purpose, upload.FileName, upload.UploadLength).
+		Scan(&upload.UUID, &upload.CreatedAt, &upload.UpdatedAt)
+	return constant.FromDB(err, "upload")
+}
+
+func (u *Upload) GetByUUID(ctx context.Context, uuid string) (*db.FileUpload, error) {
+	upload := &db.FileUpload{}
+	err := u.db.GetContext(ctx, upload, GetByUUIDQuery, uuid)
+	return upload, constant.FromDB(err, "upload")
+}
+
+// Advance records a stored chunk and moves the offset from one position to
+// the next. It fails with ErrUploadOffset when another request moved the
+// offset first, so two clients racing on one upload cannot both append.
+func (u *Upload) Advance(ctx context.Context, tx *sqlx.Tx, uuid string, from, to int64, chunkKey string) error {
+	result, err := tx.ExecContext(ctx, AdvanceQuery, uuid, from, to, chunkKey)
+	if err != nil {
+		return constant.FromDB(err, "upload")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return constant.ErrUploadOffset
+	}
+
+	return nil
+}
+
+// Complete links a fully received upload to the file it was stored as.
+func (u *Upload) Complete(ctx context.Context, tx *sqlx.Tx, uuid, fileUUID string) error {
+	result, err := tx.ExecContext(ctx, CompleteQuery, uuid, fileUUID)
+	if err != nil {
+		return constant.FromDB(err, "upload")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return constant.ErrUploadComplete
+	}
+
+	return nil
+}
+
+func (u *Upload) Delete(ctx context.Context, tx *sqlx.Tx, uuid string) error {
+	_, err := tx.ExecContext(ctx, DeleteQuery, uuid)
+	return constant.FromDB(err, "upload")
+}
+
+// DeleteStale removes up to limit uploads that have not changed for age,
+// completed or not. Only the UUID and chunk keys of the removed uploads are
+// returned, so the caller can delete the chunks they left behind.
+func (u *Upload) DeleteStale(ctx context.Context, tx *sqlx.Tx, age time.Duration, limit int) ([]*db.FileUpload, error) {
+	var uploads []*db.FileUpload
+	err := tx.SelectContext(ctx, &uploads, DeleteStaleQuery, age.Seconds(), limit)
+	return uploads, constant.FromDB(err, "upload")
+}
+
+// PutChunk stores one chunk and returns its key. Keys carry a random suffix
+// so two requests sending the same offset never overwrite each other; the
+// one that loses the Advance race deletes its own chunk.
+func (u *Upload) PutChunk(ctx context.Context, uuid string, offset int64, r io.Reader, size int64) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("uploads/%s/%020d-%s", uuid, offset, hex.EncodeToString(suffix))
+	if err := u.storage.Put(ctx, key, r, size, "application/octet-stream"); err != nil {
+		return "", fmt.Errorf("failed to store chunk: %v", err)
+	}
+
+	return key, nil
+}
+
+// Open returns the upload's content by reading its chunks one after the
+// other, opening each only when the previous one is exhausted.
+func (u *Upload) Open(ctx context.Context, upload *db.FileUpload) io.ReadCloser {
+	return &chunkReader{ctx: ctx, storage: u.storage, keys: upload.ChunkKeys}
+}
+
+// DeleteChunks removes stored chunks, carrying on past failures so one
+// missing object does not leave the rest behind.
+func (u *Upload) DeleteChunks(ctx context.Context, keys []string) error {
+	var firstErr error
+	for _, key := range keys {
+		if err := u.storage.Delete(ctx, key); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to delete chunk: %v", err)
+		}
+	}
+	return firstErr
+}
+
+type chunkReader struct {
+	ctx     context.Context
+	storage storage.IStorage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.keys) == 0 {
+				return 0, io.EOF
+			}
+
+			rc, err := c.storage.Get(c.ctx, c.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to read chunk: %v", err)
+			}
+			c.current, c.keys = rc, c.keys[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	err := c.current.Close()
+	c.current = nil
+	return err
+}
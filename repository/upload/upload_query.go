@@ -0,0 +1,41 @@
+package upload
+
+const (
+	CreateQuery = `
+		INSERT INTO file_uploads (uploaded_by, purpose, file_name, upload_length)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid, created_at, updated_at
+	`
+
+	GetByUUIDQuery = `
+		SELECT uuid, uploaded_by, purpose, file_name, upload_length, upload_offset, chunk_keys, file_uuid, completed_at, created_at, updated_at
+		FROM file_uploads WHERE uuid = $1
+	`
+
+	AdvanceQuery = `
+		UPDATE file_uploads
+		SET upload_offset = $3, chunk_keys = array_append(chunk_keys, $4), updated_at = CURRENT_TIMESTAMP
+		WHERE uuid = $1 AND upload_offset = $2 AND completed_at IS NULL
+	`
+
+	CompleteQuery = `
+		UPDATE file_uploads
+		SET file_uuid = $2, completed_at = CURRENT_TIMESTAMP, chunk_keys = '{}', updated_at = CURRENT_TIMESTAMP
+		WHERE uuid = $1 AND upload_offset = upload_length AND completed_at IS NULL
+	`
+
+	DeleteQuery = `DELETE FROM file_uploads WHERE uuid = $1`
+
+	// DeleteStaleQuery removes a batch of uploads untouched for $1 seconds. The
+	// offset guard in AdvanceQuery makes a chunk racing the removal fail.
+	DeleteStaleQuery = `
+		DELETE FROM file_uploads
+		WHERE uuid IN (
+			SELECT uuid FROM file_uploads
+			WHERE updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+			ORDER BY updated_at
+			LIMIT $2
+		)
+		RETURNING uuid, chunk_keys
+	`
+)
@@ -10,15 +10,16 @@ import (
 	modelDB "github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type IUser interface {
 	Login(ctx context.Context, email, password string) (*modelDB.User, error)
 	Register(ctx context.Context, tx *sqlx.Tx, user *modelDB.User, plainPassword string) (*modelDB.User, error)
-	GetAll() ([]*modelDB.User, error)
+	GetAll(ctx context.Context) ([]*modelDB.User, error)
 	GetByUUID(ctx context.Context, uuid string) (*modelDB.User, error)
-	GetStudentList(queryParam *dto.StudentListRequest) ([]*modelDB.User, []*modelDB.Student, int, error)
+	GetStudentList(ctx context.Context, queryParam *dto.StudentListRequest) ([]*modelDB.User, []*modelDB.Student, int, error)
 }
 
 type User struct {
@@ -37,8 +38,10 @@ func (u *User) Login(ctx context.Context, email, plainPassword string) (*modelDB
 	err := u.db.QueryRowContext(ctx, getUserByEmailQuery, email).
 		Scan(&user.UUID, &user.Email, &user.Name, &user.PasswordHash, &user.Role, &user.CreatedAt)
 	if err != nil {
+		// Unknown emails get the same answer as wrong passwords so that
+		// login cannot be used to probe which accounts exist.
 		if err == sql.ErrNoRows {
-			return nil, constant.ErrUserNotFound
+			return nil, constant.ErrInvalidCredentials
 		}
 		return nil, err
 	}
@@ -62,7 +65,10 @@ func (u *User) Register(ctx context.Context, tx *sqlx.Tx, user *modelDB.User, pl
 	err = tx.QueryRowContext(ctx, createUserQuery,
 		user.Email, user.Name, user.PasswordHash, user.Role).Scan(&user.UUID, &user.Email, &user.Name, &user.PasswordHash, &user.Role, &user.CreatedAt)
 	if err != nil {
-		return nil, err
+		if constant.IsUniqueViolation(err) {
+			return nil, constant.ErrEmailTaken
+		}
+		return nil, constant.FromDB(err, "user")
 	}
 
 	user.PasswordHash = ""
@@ -70,10 +76,10 @@ func (u *User) Register(ctx context.Context, tx *sqlx.Tx, user *modelDB.User, pl
 	return user, nil
 }
 
-func (u *User) GetAll() ([]*modelDB.User, error) {
+func (u *User) GetAll(ctx context.Context) ([]*modelDB.User, error) {
 	var users []*modelDB.User
 
-	rows, err := u.db.Query(getAllUsersQuery)
+	rows, err := u.db.QueryContext(ctx, getAllUsersQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -104,13 +110,13 @@ func (u *User) GetByUUID(ctx context.Context, uuid string) (*modelDB.User, error
 		if err == sql.ErrNoRows {
 			return nil, constant.ErrUserNotFound
 		}
-		return nil, err
+		return nil, constant.FromDB(err, "user")
 	}
 	user.PasswordHash = ""
 	return &user, nil
 }
 
-func (u *User) GetStudentList(queryParam *dto.StudentListRequest) ([]*modelDB.User, []*modelDB.Student, int, error) {
+func (u *User) GetStudentList(ctx context.Context, queryParam *dto.StudentListRequest) ([]*modelDB.User, []*modelDB.Student, int, error) {
 	var users []*modelDB.User
 	var students []*modelDB.Student
 	var args []interface{}
@@ -130,16 +136,40 @@ func (u *User) GetStudentList(queryParam *dto.StudentListRequest) ([]*modelDB.Us
 		argIndex++
 	}
 
+	// Skill, proficiency and endorsement filters must all hold for the same
+	// student skill, so they share one EXISTS
+	var skillConditions []string
 	if queryParam.Skill != nil && *queryParam.Skill != "" {
-		whereConditions = append(whereConditions, fmt.Sprintf(`EXISTS (
-			SELECT 1 FROM student_skills ss 
-			JOIN skills sk ON ss.skill_uuid = sk.uuid 
-			WHERE ss.student_uuid = s.uuid AND sk.name ILIKE $%d
-		)`, argIndex))
+		skillConditions = append(skillConditions, fmt.Sprintf("sk.name ILIKE $%d", argIndex))
 		args = append(args, "%"+*queryParam.Skill+"%")
 		argIndex++
 	}
 
+	if queryParam.MinProficiency != nil && *queryParam.MinProficiency != "" {
+		skillConditions = append(skillConditions, fmt.Sprintf("ss.proficiency = ANY($%d)", argIndex))
+		args = append(args, pq.Array(dto.ProficienciesFrom(*queryParam.MinProficiency)))
+		argIndex++
+	}
+
+	if queryParam.Endorsed {
+		skillConditions = append(skillConditions, `EXISTS (
+			SELECT 1 FROM skill_endorsements se
+			WHERE se.student_uuid = ss.student_uuid AND se.skill_uuid = ss.skill_uuid
+		)`)
+	}
+
+	if len(skillConditions) > 0 {
+		whereConditions = append(whereConditions, `EXISTS (
+			SELECT 1 FROM student_skills ss 
+			JOIN skills sk ON ss.skill_uuid = sk.uuid 
+			WHERE ss.student_uuid = s.uuid AND `+strings.Join(skillConditions, " AND ")+`
+		)`)
+	}
+
+	if queryParam.Cursor != nil {
+		return u.getStudentListAfter(ctx, queryParam, whereConditions, args, argIndex)
+	}
+
 	// Build the complete queries
 	userQuery := getStudentListUsersQuery
 	studentQuery := getStudentListStudentsQuery
@@ -154,14 +184,14 @@ func (u *User) GetStudentList(queryParam *dto.StudentListRequest) ([]*modelDB.Us
 
 	// Get total count
 	var totalCount int
-	err := u.db.Get(&totalCount, countQuery, args...)
+	err := u.db.GetContext(ctx, &totalCount, countQuery, args...)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 
 	// Add ORDER BY and pagination
-	userQuery += " ORDER BY u.email"
-	studentQuery += " ORDER BY u.email"
+	userQuery += " ORDER BY u.email, u.uuid"
+	studentQuery += " ORDER BY u.email, u.uuid"
 
 	if queryParam.Limit > 0 {
 		userQuery += fmt.Sprintf(" LIMIT $%d", argIndex)
@@ -178,15 +208,52 @@ func (u *User) GetStudentList(queryParam *dto.StudentListRequest) ([]*modelDB.Us
 	}
 
 	// Execute the queries
-	err = u.db.Select(&users, userQuery, args...)
+	err = u.db.SelectContext(ctx, &users, userQuery, args...)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 
-	err = u.db.Select(&students, studentQuery, args...)
+	err = u.db.SelectContext(ctx, &students, studentQuery, args...)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 
 	return users, students, totalCount, nil
 }
+
+// getStudentListAfter is the keyset variant of GetStudentList, seeking past
+// the cursor on (email, uuid) and fetching one extra row to detect a next page.
+func (u *User) getStudentListAfter(ctx context.Context, queryParam *dto.StudentListRequest, whereConditions []string, args []interface{}, argIndex int) ([]*modelDB.User, []*modelDB.Student, int, error) {
+	var users []*modelDB.User
+	var students []*modelDB.Student
+
+	if queryParam.After != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("(u.email, u.uuid) > ($%d, $%d::uuid)", argIndex, argIndex+1))
+		args = append(args, queryParam.After.Key, queryParam.After.UUID)
+		argIndex += 2
+	}
+
+	userQuery := getStudentListUsersQuery
+	studentQuery := getStudentListStudentsQuery
+
+	if len(whereConditions) > 0 {
+		whereClause := " AND " + strings.Join(whereConditions, " AND ")
+		userQuery += whereClause
+		studentQuery += whereClause
+	}
+
+	suffix := fmt.Sprintf(" ORDER BY u.email, u.uuid LIMIT $%d", argIndex)
+	userQuery += suffix
+	studentQuery += suffix
+	args = append(args, queryParam.Limit+1)
+
+	if err := u.db.SelectContext(ctx, &users, userQuery, args...); err != nil {
+		return nil, nil, 0, err
+	}
+
+	if err := u.db.SelectContext(ctx, &students, studentQuery, args...); err != nil {
+		return nil, nil, 0, err
+	}
+
+	return users, students, 0, nil
+}
@@ -0,0 +1,148 @@
+package routing
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/helper"
+	"github.com/HPNV/growlink-backend/service/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// authenticate validates the bearer access token and stores the caller's
+// identity in the context. Route groups opt in by attaching it.
+func (r *Route) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Error(constant.ErrUnauthenticated)
+			c.Abort()
+			return
+		}
+
+		claims, err := helper.ParseAccessToken(r.auth, strings.TrimSpace(token))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(constant.ContextUserUUID, claims.Subject)
+		c.Set(constant.ContextRole, claims.Role)
+		if claims.StudentUUID != "" {
+			c.Set(constant.ContextStudentUUID, claims.StudentUUID)
+		}
+		if claims.BusinessUUID != "" {
+			c.Set(constant.ContextBusinessUUID, claims.BusinessUUID)
+		}
+
+		c.Next()
+	}
+}
+
+func actorFromContext(c *gin.Context) policy.Actor {
+	return policy.Actor{
+		UserUUID:     c.GetString(constant.ContextUserUUID),
+		Role:         c.GetString(constant.ContextRole),
+		StudentUUID:  c.GetString(constant.ContextStudentUUID),
+		BusinessUUID: c.GetString(constant.ContextBusinessUUID),
+	}
+}
+
+// authorize runs a policy check against the authenticated caller and aborts
+// with the policy error when it fails. It must be attached after authenticate.
+func (r *Route) authorize(check func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := check(r.policy, actorFromContext(c), c); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (r *Route) requireAdmin() gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.RequireAdmin(c.Request.Context(), actor)
+	})
+}
+
+func (r *Route) requireBusinessOwner(param string) gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.CanManageBusiness(c.Request.Context(), actor, c.Param(param))
+	})
+}
+
+func (r *Route) requireStudentOwner(param string) gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.CanManageStudent(c.Request.Context(), actor, c.Param(param))
+	})
+}
+
+func (r *Route) requireProjectOwner(param string) gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.CanManageProject(c.Request.Context(), actor, c.Param(param))
+	})
+}
+
+func (r *Route) requireFileOwner(param string) gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.CanManageFile(c.Request.Context(), actor, c.Param(param))
+	})
+}
+
+func (r *Route) requireUploadOwner(param string) gin.HandlerFunc {
+	return r.authorize(func(p policy.IPolicy, actor policy.Actor, c *gin.Context) error {
+		return p.CanManageUpload(c.Request.Context(), actor, c.Param(param))
+	})
+}
+
+var errorStatus = map[constant.Kind]int{
+	constant.KindValidation:   http.StatusBadRequest,
+	constant.KindUnauthorized: http.StatusUnauthorized,
+	constant.KindForbidden:    http.StatusForbidden,
+	constant.KindNotFound:     http.StatusNotFound,
+	constant.KindConflict:     http.StatusConflict,
+}
+
+// renderErrors turns the last error recorded with c.Error into the JSON error
+// envelope. Errors that are not *constant.Error are logged and reported as a
+// generic internal error so driver messages never reach clients.
+func (r *Route) renderErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+
+		var appErr *constant.Error
+		if !errors.As(err, &appErr) || appErr.Kind == constant.KindInternal {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+			appErr = constant.Internal(err)
+		}
+
+		status, ok := errorStatus[appErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+
+		body := gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		}
+		if appErr.Details != nil {
+			body["details"] = appErr.Details
+		}
+
+		c.JSON(status, gin.H{"error": body})
+	}
+}
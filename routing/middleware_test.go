@@ -0,0 +1,186 @@
+package routing
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/helper"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/service/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testAuth = config.AuthConfig{Secret: "secret", Issuer: "growlink", AccessTTL: time.Minute}
+
+// testEngine serves the authenticated actor at /me and a business only its
+// owner may update at /business/:uuid.
+func testEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := &Route{
+		auth:   testAuth,
+		policy: policy.NewPolicy(sqltest.Open(func(string, []driver.NamedValue) *sqltest.Rows { return nil }).Registry(nil)),
+	}
+
+	engine := gin.New()
+	engine.Use(r.renderErrors())
+	engine.GET("/me", r.authenticate(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, actorFromContext(c))
+	})
+	engine.PUT("/business/:uuid", r.authenticate(), r.requireBusinessOwner("uuid"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return engine
+}
+
+// sign builds the access token of a business owner, letting a test tamper
+// with the claims and the signing method.
+func sign(t *testing.T, method jwt.SigningMethod, key any, edit func(*helper.AccessClaims)) string {
+	t.Helper()
+
+	claims := helper.AccessClaims{Role: "business", BusinessUUID: "business"}
+	claims.Subject = "user"
+	claims.Issuer = testAuth.Issuer
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+	if edit != nil {
+		edit(&claims)
+	}
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte(testAuth.Secret)
+	valid, _, err := helper.GenerateAccessToken(testAuth, helper.AccessClaims{Role: "business", BusinessUUID: "business", RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		code   string
+	}{
+		{"valid token", "Bearer " + valid, http.StatusOK, ""},
+		{"lowercase scheme", "bearer " + valid, http.StatusOK, ""},
+		{"missing header", "", http.StatusUnauthorized, constant.ErrUnauthenticated.Code},
+		{"missing token", "Bearer ", http.StatusUnauthorized, constant.ErrUnauthenticated.Code},
+		{"wrong scheme", "Basic " + valid, http.StatusUnauthorized, constant.ErrUnauthenticated.Code},
+		{"no scheme", valid, http.StatusUnauthorized, constant.ErrUnauthenticated.Code},
+		{"malformed token", "Bearer not-a-jwt", http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"wrong secret", "Bearer " + sign(t, jwt.SigningMethodHS256, []byte("other"), nil), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"wrong signing method", "Bearer " + sign(t, jwt.SigningMethodHS384, secret, nil), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"unsigned token", "Bearer " + sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, nil), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"expired token", "Bearer " + sign(t, jwt.SigningMethodHS256, secret, func(c *helper.AccessClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		}), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"no expiry", "Bearer " + sign(t, jwt.SigningMethodHS256, secret, func(c *helper.AccessClaims) {
+			c.ExpiresAt = nil
+		}), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"wrong issuer", "Bearer " + sign(t, jwt.SigningMethodHS256, secret, func(c *helper.AccessClaims) {
+			c.Issuer = "someone-else"
+		}), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+		{"no subject", "Bearer " + sign(t, jwt.SigningMethodHS256, secret, func(c *helper.AccessClaims) {
+			c.Subject = ""
+		}), http.StatusUnauthorized, constant.ErrInvalidToken.Code},
+	}
+
+	engine := testEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" {
+				if code := errorCode(t, w); code != tt.code {
+					t.Errorf("code = %q, want %q", code, tt.code)
+				}
+				return
+			}
+
+			var actor policy.Actor
+			if err := json.Unmarshal(w.Body.Bytes(), &actor); err != nil {
+				t.Fatal(err)
+			}
+			want := policy.Actor{UserUUID: "user", Role: "business", BusinessUUID: "business"}
+			if actor != want {
+				t.Errorf("actor = %+v, want %+v", actor, want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	owner := sign(t, jwt.SigningMethodHS256, []byte(testAuth.Secret), nil)
+	admin := sign(t, jwt.SigningMethodHS256, []byte(testAuth.Secret), func(c *helper.AccessClaims) {
+		c.Role = policy.RoleAdmin
+		c.BusinessUUID = ""
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		status int
+		code   string
+	}{
+		{"owner", owner, "/business/business", http.StatusNoContent, ""},
+		{"admin", admin, "/business/business", http.StatusNoContent, ""},
+		{"other business", owner, "/business/other", http.StatusForbidden, constant.ErrForbidden.Code},
+		{"unauthenticated", "", "/business/business", http.StatusUnauthorized, constant.ErrUnauthenticated.Code},
+	}
+
+	engine := testEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" {
+				if code := errorCode(t, w); code != tt.code {
+					t.Errorf("code = %q, want %q", code, tt.code)
+				}
+			}
+		})
+	}
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Error.Code
+}
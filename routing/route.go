@@ -1,35 +1,54 @@
 package routing
 
 import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
 	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/delivery"
+	"github.com/HPNV/growlink-backend/service/policy"
+	"github.com/HPNV/growlink-backend/storage"
 	"github.com/gin-gonic/gin"
 )
 
 type Route struct {
 	cfg      config.ServerConfig
+	auth     config.AuthConfig
+	storage  config.StorageConfig
+	policy   policy.IPolicy
 	engine   *gin.Engine
 	delivery delivery.IDelivery
 }
 
-func NewRoute(cfg config.ServerConfig, delivery delivery.IDelivery) *Route {
+func NewRoute(cfg config.ServerConfig, auth config.AuthConfig, storage config.StorageConfig, policy policy.IPolicy, delivery delivery.IDelivery) *Route {
 	gin.SetMode(cfg.Mode)
 
 	return &Route{
 		cfg:      cfg,
-		engine:   gin.Default(),
+		auth:     auth,
+		storage:  storage,
+		policy:   policy,
+		engine:   gin.New(),
 		delivery: delivery,
 	}
 }
 
+// unloggedPaths are probed constantly by the load balancer and would drown
+// out real traffic in the access log.
+var unloggedPaths = []string{"/healthz", "/readyz", "/version"}
+
 func (r *Route) SetupRoutes() {
-	r.engine = gin.Default()
+	r.engine.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: unloggedPaths}), gin.Recovery())
 
 	// Add CORS middleware
 	r.engine.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata")
+		c.Header("Access-Control-Expose-Headers", "Location, Tus-Resumable, Upload-Length, Upload-Offset")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -39,8 +58,17 @@ func (r *Route) SetupRoutes() {
 		c.Next()
 	})
 
-	// Serve static files
-	r.engine.Static("/static", "./static")
+	r.engine.Use(r.renderErrors())
+	r.engine.NoRoute(func(c *gin.Context) {
+		c.Error(constant.NotFound("route_not_found", "route not found"))
+	})
+
+	// Serve uploads kept on local disk; other drivers serve their own URLs
+	if r.storage.Driver == storage.DriverLocal {
+		r.engine.Static(r.storage.LocalURL, r.storage.LocalDir)
+	}
+
+	r.healthRoute(r.engine)
 
 	v := r.engine.Group("/v1")
 
@@ -50,8 +78,49 @@ func (r *Route) SetupRoutes() {
 	r.skillRoute(v)
 	r.projectRoute(v)
 	r.fileRoute(v)
+}
+
+// Run serves the routes until ctx is cancelled, then stops accepting
+// connections and waits up to the configured shutdown timeout for in-flight
+// requests to finish.
+func (r *Route) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:    ":" + r.cfg.Port,
+		Handler: r.engine,
+	}
 
-	r.engine.Run(":" + r.cfg.Port)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), r.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Route) healthRoute(e *gin.Engine) {
+	health := r.delivery.GetHealth()
+	e.GET("/healthz", health.Healthz)
+	e.GET("/readyz", health.Readyz)
+	e.GET("/version", health.Version)
 }
 
 func (r *Route) userRoute(g *gin.RouterGroup) {
@@ -59,80 +128,141 @@ func (r *Route) userRoute(g *gin.RouterGroup) {
 	u := g.Group("/user")
 	u.POST("/login", user.Login)
 	u.POST("/register", user.Register)
-	u.GET("", user.GetAll)
-	u.GET("/students", user.GetStudentList)
-	u.GET("/:uuid", user.GetDetail)
+	u.POST("/refresh", user.Refresh)
+	u.POST("/logout", user.Logout)
+
+	authed := u.Group("", r.authenticate())
+	authed.GET("", user.GetAll)
+	authed.GET("/students", user.GetStudentList)
+	authed.GET("/:uuid", user.GetDetail)
 }
 
 func (r *Route) businessRoute(g *gin.RouterGroup) {
 	business := r.delivery.GetBusiness()
 	b := g.Group("/business")
 
-	b.POST("", business.Create)
 	b.GET("", business.GetAll)
 	b.GET("/:uuid", business.GetByUUID)
 	b.GET("/user/:userUuid", business.GetByUserUUID)
-	b.PUT("/:uuid", business.Update)
-	b.DELETE("/:uuid", business.Delete)
+
+	authed := b.Group("", r.authenticate())
+	authed.POST("", business.Create)
+	authed.PUT("/:uuid", r.requireBusinessOwner("uuid"), business.Update)
+	authed.DELETE("/:uuid", r.requireBusinessOwner("uuid"), business.Delete)
 }
 
 func (r *Route) studentRoute(g *gin.RouterGroup) {
 	student := r.delivery.GetStudent()
 	s := g.Group("/student")
 
-	s.POST("", student.Create)
 	s.GET("", student.GetAll)
 	s.GET("/:uuid", student.GetByUUID)
 	s.GET("/user/:userUuid", student.GetByUserUUID)
-	s.PUT("/:uuid", student.Update)
-	s.DELETE("/:uuid", student.Delete)
+	s.GET("/:uuid/skills", student.GetSkills)
+	s.GET("/:uuid/endorsements", student.GetEndorsements)
+
+	authed := s.Group("", r.authenticate())
+	authed.POST("", student.Create)
+	authed.PUT("/:uuid", r.requireStudentOwner("uuid"), student.Update)
+	authed.DELETE("/:uuid", r.requireStudentOwner("uuid"), student.Delete)
 
 	// Student skills management
-	s.POST("/:uuid/skills", student.AddSkill)
-	s.DELETE("/:uuid/skills", student.RemoveSkill)
-	s.GET("/:uuid/skills", student.GetSkills)
+	authed.POST("/:uuid/skills", r.requireStudentOwner("uuid"), student.AddSkill)
+	authed.DELETE("/:uuid/skills", r.requireStudentOwner("uuid"), student.RemoveSkill)
+
+	// Skill endorsements by businesses the student worked for
+	authed.POST("/:uuid/endorsements", student.Endorse)
+
+	// Résumés, portfolios and pictures on the student's profile
+	authed.GET("/:uuid/files", student.GetFiles)
+	authed.POST("/:uuid/files", r.requireStudentOwner("uuid"), student.AttachFile)
+	authed.DELETE("/:uuid/files/:fileUuid", r.requireStudentOwner("uuid"), student.DetachFile)
+
+	// Student applications
+	authed.GET("/:uuid/applications", r.requireStudentOwner("uuid"), student.GetApplications)
+
+	// Project matching
+	authed.GET("/:uuid/recommended-projects", r.requireStudentOwner("uuid"), student.GetRecommendedProjects)
 }
 
 func (r *Route) skillRoute(g *gin.RouterGroup) {
 	skill := r.delivery.GetSkill()
 	sk := g.Group("/skill")
 
-	sk.POST("", skill.Create)
 	sk.GET("", skill.GetAll)
+	sk.GET("/categories", skill.GetCategories)
+	sk.GET("/suggest", skill.Suggest)
 	sk.GET("/:uuid", skill.GetByUUID)
-	sk.PUT("/:uuid", skill.Update)
-	sk.DELETE("/:uuid", skill.Delete)
+
+	authed := sk.Group("", r.authenticate())
+	authed.POST("", skill.Create)
+	authed.PUT("/:uuid", r.requireAdmin(), skill.Update)
+	authed.DELETE("/:uuid", r.requireAdmin(), skill.Delete)
+
+	// Taxonomy curation
+	authed.POST("/categories", r.requireAdmin(), skill.CreateCategory)
+	authed.POST("/:uuid/aliases", r.requireAdmin(), skill.AddAlias)
+	authed.DELETE("/:uuid/aliases/:alias", r.requireAdmin(), skill.RemoveAlias)
+	authed.POST("/:uuid/merge", r.requireAdmin(), skill.Merge)
 }
 
 func (r *Route) projectRoute(g *gin.RouterGroup) {
 	project := r.delivery.GetProject()
 	p := g.Group("/project")
 
-	p.POST("/business/:businessUuid", project.Create)
 	p.GET("", project.GetAll)
 	p.GET("/list", project.GetAllList)
 	p.GET("/:uuid", project.GetByUUID)
 	p.GET("/business/:businessUuid", project.GetByBusinessUUID)
-	p.PUT("/:uuid", project.Update)
-	p.DELETE("/:uuid", project.Delete)
+	p.GET("/:uuid/skills", project.GetSkills)
+	p.GET("/:uuid/students", project.GetStudents)
+	p.GET("/:uuid/history", project.GetStatusHistory)
+
+	authed := p.Group("", r.authenticate())
+	authed.POST("/business/:businessUuid", r.requireBusinessOwner("businessUuid"), project.Create)
+
+	owned := authed.Group("", r.requireProjectOwner("uuid"))
+	owned.PUT("/:uuid", project.Update)
+	owned.DELETE("/:uuid", project.Delete)
 
 	// Project skills management
-	p.POST("/:uuid/skills", project.AddSkill)
-	p.DELETE("/:uuid/skills", project.RemoveSkill)
-	p.GET("/:uuid/skills", project.GetSkills)
+	owned.POST("/:uuid/skills", project.AddSkill)
+	owned.DELETE("/:uuid/skills", project.RemoveSkill)
 
-	// Project students management
-	p.POST("/:uuid/students/:studentUuid", project.AddStudent)
-	p.DELETE("/:uuid/students/:studentUuid", project.RemoveStudent)
-	p.GET("/:uuid/students", project.GetStudents)
+	// Students join through accepted applications; owners can only remove them
+	owned.DELETE("/:uuid/students/:studentUuid", project.RemoveStudent)
+
+	// Project briefs and images
+	authed.GET("/:uuid/files", project.GetFiles)
+	owned.POST("/:uuid/files", project.AttachFile)
+	owned.DELETE("/:uuid/files/:fileUuid", project.DetachFile)
+
+	// Project applications
+	authed.POST("/:uuid/applications", project.Apply)
+	authed.POST("/:uuid/applications/:applicationUuid/withdraw", project.Withdraw)
+	owned.GET("/:uuid/applications", project.GetApplications)
+	owned.POST("/:uuid/applications/:applicationUuid/shortlist", project.Shortlist)
+	owned.POST("/:uuid/applications/:applicationUuid/accept", project.Accept)
+	owned.POST("/:uuid/applications/:applicationUuid/reject", project.Reject)
+
+	// Student matching
+	owned.GET("/:uuid/recommended-students", project.GetRecommendedStudents)
 }
 
 func (r *Route) fileRoute(g *gin.RouterGroup) {
 	file := r.delivery.GetFile()
 	f := g.Group("/file")
 
-	f.POST("/upload", file.UploadImage)
 	f.GET("/:uuid", file.GetByUUID)
-	f.DELETE("/:uuid", file.Delete)
 	f.GET("/user/:uploadedBy", file.GetByUploadedBy)
+
+	authed := f.Group("", r.authenticate())
+	authed.POST("/upload", file.Upload)
+	authed.DELETE("/:uuid", r.requireFileOwner("uuid"), file.Delete)
+
+	// Resumable uploads for files too large for a single request
+	authed.POST("/uploads", file.CreateUpload)
+	authed.HEAD("/uploads/:uuid", r.requireUploadOwner("uuid"), file.HeadUpload)
+	authed.PATCH("/uploads/:uuid", r.requireUploadOwner("uuid"), file.PatchUpload)
+	authed.DELETE("/uploads/:uuid", r.requireUploadOwner("uuid"), file.DeleteUpload)
 }
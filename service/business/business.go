@@ -1,6 +1,8 @@
 package business
 
 import (
+	"context"
+
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
@@ -8,12 +10,12 @@ import (
 )
 
 type IBusiness interface {
-	Create(userUUID string, req *dto.BusinessRequest) (*dto.BusinessResponse, error)
-	GetByUUID(uuid string) (*dto.BusinessResponse, error)
-	GetByUserUUID(userUUID string) (*dto.BusinessResponse, error)
-	Update(uuid string, req *dto.BusinessRequest) (*dto.BusinessResponse, error)
-	Delete(uuid string) error
-	GetAll() ([]*dto.BusinessResponse, error)
+	Create(ctx context.Context, userUUID string, req *dto.BusinessRequest) (*dto.BusinessResponse, error)
+	GetByUUID(ctx context.Context, uuid string) (*dto.BusinessResponse, error)
+	GetByUserUUID(ctx context.Context, userUUID string) (*dto.BusinessResponse, error)
+	Update(ctx context.Context, uuid string, req *dto.BusinessRequest) (*dto.BusinessResponse, error)
+	Delete(ctx context.Context, uuid string) error
+	GetAll(ctx context.Context) ([]*dto.BusinessResponse, error)
 }
 
 type Business struct {
@@ -26,14 +28,14 @@ func NewBusiness(repo repository.IRegistry) IBusiness {
 	}
 }
 
-func (b *Business) Create(userUUID string, req *dto.BusinessRequest) (*dto.BusinessResponse, error) {
+func (b *Business) Create(ctx context.Context, userUUID string, req *dto.BusinessRequest) (*dto.BusinessResponse, error) {
 	business := &db.Business{
 		UserUUID:    userUUID,
 		CompanyName: req.CompanyName,
 	}
 
-	err := b.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return b.repo.GetBusiness().Create(tx, business)
+	err := b.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return b.repo.GetBusiness().Create(ctx, tx, business)
 	})
 
 	if err != nil {
@@ -47,8 +49,8 @@ func (b *Business) Create(userUUID string, req *dto.BusinessRequest) (*dto.Busin
 	}, nil
 }
 
-func (b *Business) GetByUUID(uuid string) (*dto.BusinessResponse, error) {
-	business, err := b.repo.GetBusiness().GetByUUID(uuid)
+func (b *Business) GetByUUID(ctx context.Context, uuid string) (*dto.BusinessResponse, error) {
+	business, err := b.repo.GetBusiness().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -60,8 +62,8 @@ func (b *Business) GetByUUID(uuid string) (*dto.BusinessResponse, error) {
 	}, nil
 }
 
-func (b *Business) GetByUserUUID(userUUID string) (*dto.BusinessResponse, error) {
-	business, err := b.repo.GetBusiness().GetByUserUUID(userUUID)
+func (b *Business) GetByUserUUID(ctx context.Context, userUUID string) (*dto.BusinessResponse, error) {
+	business, err := b.repo.GetBusiness().GetByUserUUID(ctx, userUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,9 +75,9 @@ func (b *Business) GetByUserUUID(userUUID string) (*dto.BusinessResponse, error)
 	}, nil
 }
 
-func (b *Business) Update(uuid string, req *dto.BusinessRequest) (*dto.BusinessResponse, error) {
+func (b *Business) Update(ctx context.Context, uuid string, req *dto.BusinessRequest) (*dto.BusinessResponse, error) {
 	// First get existing business
-	existing, err := b.repo.GetBusiness().GetByUUID(uuid)
+	existing, err := b.repo.GetBusiness().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -83,8 +85,8 @@ func (b *Business) Update(uuid string, req *dto.BusinessRequest) (*dto.BusinessR
 	// Update fields
 	existing.CompanyName = req.CompanyName
 
-	err = b.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return b.repo.GetBusiness().Update(tx, existing)
+	err = b.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return b.repo.GetBusiness().Update(ctx, tx, existing)
 	})
 
 	if err != nil {
@@ -98,14 +100,14 @@ func (b *Business) Update(uuid string, req *dto.BusinessRequest) (*dto.BusinessR
 	}, nil
 }
 
-func (b *Business) Delete(uuid string) error {
-	return b.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return b.repo.GetBusiness().Delete(tx, uuid)
+func (b *Business) Delete(ctx context.Context, uuid string) error {
+	return b.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return b.repo.GetBusiness().Delete(ctx, tx, uuid)
 	})
 }
 
-func (b *Business) GetAll() ([]*dto.BusinessResponse, error) {
-	businesses, err := b.repo.GetBusiness().GetAll()
+func (b *Business) GetAll(ctx context.Context) ([]*dto.BusinessResponse, error) {
+	businesses, err := b.repo.GetBusiness().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1,19 +1,36 @@
 package file
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"mime/multipart"
+	"slices"
+	"sort"
 
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
 	"github.com/jmoiron/sqlx"
 )
 
 type IFile interface {
-	UploadImage(file multipart.File, header *multipart.FileHeader, uploadedBy string) (*dto.FileUploadResponse, error)
-	GetByUUID(uuid string) (*dto.FileUploadResponse, error)
-	Delete(uuid string) error
-	GetByUploadedBy(uploadedBy string) ([]*dto.FileUploadResponse, error)
+	Upload(ctx context.Context, file multipart.File, header *multipart.FileHeader, uploadedBy, purpose string) (*dto.FileUploadResponse, error)
+	GetByUUID(ctx context.Context, uuid string) (*dto.FileUploadResponse, error)
+	Delete(ctx context.Context, uuid string) error
+	GetByUploadedBy(ctx context.Context, uploadedBy string) ([]*dto.FileUploadResponse, error)
+	AttachToStudent(ctx context.Context, studentUUID, fileUUID string) error
+	DetachFromStudent(ctx context.Context, studentUUID, fileUUID string) error
+	GetStudentFiles(ctx context.Context, studentUUID string) ([]*dto.FileUploadResponse, error)
+	AttachToProject(ctx context.Context, projectUUID, fileUUID string) error
+	DetachFromProject(ctx context.Context, projectUUID, fileUUID string) error
+	GetProjectFiles(ctx context.Context, projectUUID string) ([]*dto.FileUploadResponse, error)
+	CreateUpload(ctx context.Context, uploadedBy, purpose, fileName string, length int64) (*dto.UploadResponse, error)
+	GetUpload(ctx context.Context, uuid string) (*dto.UploadResponse, error)
+	PatchUpload(ctx context.Context, uuid string, offset int64, r io.Reader, size int64) (*dto.UploadResponse, error)
+	DeleteUpload(ctx context.Context, uuid string) error
+	CleanupUploads(ctx context.Context) (int, error)
 }
 
 type File struct {
@@ -26,78 +43,244 @@ func NewFile(repo repository.IRegistry) IFile {
 	}
 }
 
-func (f *File) UploadImage(file multipart.File, header *multipart.FileHeader, uploadedBy string) (*dto.FileUploadResponse, error) {
-	var result *dto.FileUploadResponse
+// Upload stores a file for the given purpose after checking it against the
+// purpose's upload policy. Files over MaxSimpleUploadSize have to use the
+// resumable upload endpoints.
+func (f *File) Upload(ctx context.Context, file multipart.File, header *multipart.FileHeader, uploadedBy, purpose string) (*dto.FileUploadResponse, error) {
+	defer file.Close()
 
-	err := f.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		fileRecord, err := f.repo.GetFile().UploadImage(tx, file, header, uploadedBy)
-		if err != nil {
-			return err
-		}
+	policy, ok := config.UploadPolicies[purpose]
+	if !ok {
+		return nil, constant.InvalidPurpose(purpose, purposes())
+	}
+	if maxSize := min(policy.MaxSize, config.MaxSimpleUploadSize); header.Size > maxSize {
+		return nil, constant.FileTooLarge(header.Size, maxSize)
+	}
 
-		result = &dto.FileUploadResponse{
-			UUID:         fileRecord.UUID,
-			OriginalName: fileRecord.OriginalName,
-			FileName:     fileRecord.FileName,
-			FilePath:     fileRecord.FilePath,
-			FileSize:     fileRecord.FileSize,
-			MimeType:     fileRecord.MimeType,
-			URL:          fmt.Sprintf("/static/images/%s", fileRecord.FileName),
-			CreatedAt:    fileRecord.CreatedAt,
-		}
-		return nil
+	var fileRecord *db.File
+	var keys []string
+
+	err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		fileRecord, keys, err = f.repo.GetFile().Upload(ctx, tx, file, header.Size, header.Filename, uploadedBy, purpose)
+		return err
 	})
 
 	if err != nil {
+		f.discardObjects(ctx, keys)
 		return nil, err
 	}
 
-	return result, nil
+	return f.toResponse(ctx, fileRecord, fileRecord.Variants)
 }
 
-func (f *File) GetByUUID(uuid string) (*dto.FileUploadResponse, error) {
-	fileRecord, err := f.repo.GetFile().GetByUUID(uuid)
+func (f *File) GetByUUID(ctx context.Context, uuid string) (*dto.FileUploadResponse, error) {
+	fileRecord, err := f.repo.GetFile().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.FileUploadResponse{
-		UUID:         fileRecord.UUID,
-		OriginalName: fileRecord.OriginalName,
-		FileName:     fileRecord.FileName,
-		FilePath:     fileRecord.FilePath,
-		FileSize:     fileRecord.FileSize,
-		MimeType:     fileRecord.MimeType,
-		URL:          fmt.Sprintf("/static/images/%s", fileRecord.FileName),
-		CreatedAt:    fileRecord.CreatedAt,
-	}, nil
+	variants, err := f.repo.GetFile().GetVariants(ctx, []string{uuid})
+	if err != nil {
+		return nil, err
+	}
+
+	return f.toResponse(ctx, fileRecord, variants[uuid])
 }
 
-func (f *File) Delete(uuid string) error {
-	return f.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return f.repo.GetFile().Delete(tx, uuid)
+// Delete removes a file and, once that has committed, its stored objects.
+func (f *File) Delete(ctx context.Context, uuid string) error {
+	var keys []string
+	err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		keys, err = f.repo.GetFile().Delete(ctx, tx, uuid)
+		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	return f.repo.GetFile().DeleteObjects(ctx, keys)
+}
+
+// discardObjects removes the objects stored by an upload whose transaction
+// did not commit. It runs even if ctx was cancelled, which is often why the
+// transaction failed.
+func (f *File) discardObjects(ctx context.Context, keys []string) {
+	f.repo.GetFile().DeleteObjects(context.WithoutCancel(ctx), keys)
 }
 
-func (f *File) GetByUploadedBy(uploadedBy string) ([]*dto.FileUploadResponse, error) {
-	files, err := f.repo.GetFile().GetByUploadedBy(uploadedBy)
+func (f *File) GetByUploadedBy(ctx context.Context, uploadedBy string) ([]*dto.FileUploadResponse, error) {
+	files, err := f.repo.GetFile().GetByUploadedBy(ctx, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.toResponses(ctx, files)
+}
+
+// AttachToStudent lists one of the student's own uploads on their profile.
+func (f *File) AttachToStudent(ctx context.Context, studentUUID, fileUUID string) error {
+	student, err := f.repo.GetStudent().GetByUUID(ctx, studentUUID)
+	if err != nil {
+		return err
+	}
+
+	if err := f.checkAttachable(ctx, fileUUID, student.UserUUID, config.AttachStudent); err != nil {
+		return err
+	}
+
+	return f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetFile().AttachToStudent(ctx, tx, studentUUID, fileUUID)
+	})
+}
+
+func (f *File) DetachFromStudent(ctx context.Context, studentUUID, fileUUID string) error {
+	return f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetFile().DetachFromStudent(ctx, tx, studentUUID, fileUUID)
+	})
+}
+
+func (f *File) GetStudentFiles(ctx context.Context, studentUUID string) ([]*dto.FileUploadResponse, error) {
+	files, err := f.repo.GetFile().GetByStudentUUID(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.toResponses(ctx, files)
+}
+
+// AttachToProject attaches an upload of the owning business's user to the
+// project.
+func (f *File) AttachToProject(ctx context.Context, projectUUID, fileUUID string) error {
+	project, err := f.repo.GetProject().GetByUUID(ctx, projectUUID)
+	if err != nil {
+		return err
+	}
+
+	business, err := f.repo.GetBusiness().GetByUUID(ctx, project.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	if err := f.checkAttachable(ctx, fileUUID, business.UserUUID, config.AttachProject); err != nil {
+		return err
+	}
+
+	return f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetFile().AttachToProject(ctx, tx, projectUUID, fileUUID)
+	})
+}
+
+func (f *File) DetachFromProject(ctx context.Context, projectUUID, fileUUID string) error {
+	return f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetFile().DetachFromProject(ctx, tx, projectUUID, fileUUID)
+	})
+}
+
+func (f *File) GetProjectFiles(ctx context.Context, projectUUID string) ([]*dto.FileUploadResponse, error) {
+	files, err := f.repo.GetFile().GetByProjectUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.toResponses(ctx, files)
+}
+
+// checkAttachable makes sure the file was uploaded by the owner of the
+// target, is not an image variant and has a purpose allowed on the target.
+func (f *File) checkAttachable(ctx context.Context, fileUUID, ownerUUID, target string) error {
+	file, err := f.repo.GetFile().GetByUUID(ctx, fileUUID)
+	if err != nil || file.UploadedBy != ownerUUID || file.ParentUUID != nil {
+		return constant.ErrFileNotOwned
+	}
+
+	if !slices.Contains(config.UploadPolicies[file.Purpose].AttachTo, target) {
+		return constant.ErrNotAttachable
+	}
+
+	return nil
+}
+
+// toResponses builds the API view of many files, loading their variants in
+// one query.
+func (f *File) toResponses(ctx context.Context, files []*db.File) ([]*dto.FileUploadResponse, error) {
+	uuids := make([]string, len(files))
+	for i, file := range files {
+		uuids[i] = file.UUID
+	}
+
+	variants, err := f.repo.GetFile().GetVariants(ctx, uuids)
 	if err != nil {
 		return nil, err
 	}
 
 	var responses []*dto.FileUploadResponse
 	for _, file := range files {
-		responses = append(responses, &dto.FileUploadResponse{
-			UUID:         file.UUID,
-			OriginalName: file.OriginalName,
-			FileName:     file.FileName,
-			FilePath:     file.FilePath,
-			FileSize:     file.FileSize,
-			MimeType:     file.MimeType,
-			URL:          fmt.Sprintf("/static/images/%s", file.FileName),
-			CreatedAt:    file.CreatedAt,
-		})
+		response, err := f.toResponse(ctx, file, variants[file.UUID])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
 	}
 
 	return responses, nil
 }
+
+// toResponse builds the API view of a file and its variants, with download
+// URLs supplied by the storage driver.
+func (f *File) toResponse(ctx context.Context, file *db.File, variants []*db.File) (*dto.FileUploadResponse, error) {
+	url, err := f.repo.GetFile().URL(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.FileUploadResponse{
+		UUID:         file.UUID,
+		OriginalName: file.OriginalName,
+		FileName:     file.FileName,
+		FilePath:     file.FilePath,
+		FileSize:     file.FileSize,
+		MimeType:     file.MimeType,
+		Purpose:      file.Purpose,
+		URL:          url,
+		CreatedAt:    file.CreatedAt,
+	}
+
+	for _, variant := range variants {
+		variantURL, err := f.repo.GetFile().URL(ctx, variant)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.Variants == nil {
+			response.Variants = make(map[string]*dto.FileVariantResponse, len(variants))
+		}
+		response.Variants[*variant.Variant] = &dto.FileVariantResponse{
+			URL:      variantURL,
+			Width:    derefInt(variant.Width),
+			Height:   derefInt(variant.Height),
+			FileSize: variant.FileSize,
+			MimeType: variant.MimeType,
+		}
+	}
+
+	return response, nil
+}
+
+func derefInt(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// purposes lists the known upload purposes in a stable order.
+func purposes() []string {
+	names := make([]string, 0, len(config.UploadPolicies))
+	for name := range config.UploadPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,237 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/jmoiron/sqlx"
+)
+
+// cleanupBatchSize is how many stale uploads CleanupUploads removes per
+// transaction.
+const cleanupBatchSize = 100
+
+// CreateUpload starts a resumable upload of length bytes. The length is
+// checked against the purpose's policy up front, the type and its size limit
+// on the first chunk and the content once it has all arrived.
+func (f *File) CreateUpload(ctx context.Context, uploadedBy, purpose, fileName string, length int64) (*dto.UploadResponse, error) {
+	policy, ok := config.UploadPolicies[purpose]
+	if !ok {
+		return nil, constant.InvalidPurpose(purpose, purposes())
+	}
+	if length <= 0 {
+		return nil, constant.Validation("invalid_upload_length", "upload length must be positive")
+	}
+	if length > policy.MaxSize {
+		return nil, constant.FileTooLarge(length, policy.MaxSize)
+	}
+
+	upload := &db.FileUpload{
+		UploadedBy:   uploadedBy,
+		Purpose:      purpose,
+		FileName:     fileName,
+		UploadLength: length,
+	}
+
+	err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetUpload().Create(ctx, tx, upload)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toUploadResponse(upload, nil), nil
+}
+
+func (f *File) GetUpload(ctx context.Context, uuid string) (*dto.UploadResponse, error) {
+	upload, err := f.repo.GetUpload().GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clients that lost the final PATCH response find the file here
+	var file *dto.FileUploadResponse
+	if upload.FileUUID != nil {
+		file, err = f.GetByUUID(ctx, *upload.FileUUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return toUploadResponse(upload, file), nil
+}
+
+// PatchUpload appends a chunk of size bytes at offset. Once the last byte has
+// arrived the chunks are assembled into a file and the response carries it.
+// A chunk is only kept once all of it has arrived, so after a dropped
+// connection the client resends it from the offset it gets back from HEAD.
+func (f *File) PatchUpload(ctx context.Context, uuid string, offset int64, r io.Reader, size int64) (*dto.UploadResponse, error) {
+	upload, err := f.repo.GetUpload().GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt != nil {
+		return nil, constant.ErrUploadComplete
+	}
+	if offset != upload.UploadOffset {
+		return nil, constant.UploadOffsetMismatch(offset, upload.UploadOffset)
+	}
+	if offset+size > upload.UploadLength {
+		return nil, constant.ErrUploadLength
+	}
+
+	if size > 0 {
+		// The size range and the chunk cap bound how many objects one
+		// upload can leave in storage; only the last chunk may be short
+		last := offset+size == upload.UploadLength
+		if size > config.MaxChunkSize || (size < config.MinChunkSize && !last) {
+			return nil, constant.ChunkSize(size, config.MinChunkSize, config.MaxChunkSize)
+		}
+		if len(upload.ChunkKeys) >= config.MaxUploadChunks {
+			return nil, constant.ErrTooManyChunks
+		}
+
+		// Whatever the head of the file rules out is rejected right away
+		if offset == 0 {
+			r, err = f.repo.GetFile().Precheck(r, upload.UploadLength, upload.Purpose)
+			if err != nil {
+				f.discardIfInvalid(ctx, upload, err)
+				return nil, err
+			}
+		}
+
+		key, err := f.repo.GetUpload().PutChunk(ctx, uuid, offset, r, size)
+		if err != nil {
+			return nil, err
+		}
+
+		err = f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+			return f.repo.GetUpload().Advance(ctx, tx, uuid, offset, offset+size, key)
+		})
+		if err != nil {
+			f.repo.GetUpload().DeleteChunks(ctx, []string{key})
+			return nil, err
+		}
+
+		upload.UploadOffset += size
+		upload.ChunkKeys = append(upload.ChunkKeys, key)
+	}
+
+	// An empty PATCH at the end retries a completion that failed before
+	if upload.UploadOffset < upload.UploadLength {
+		return toUploadResponse(upload, nil), nil
+	}
+
+	file, err := f.completeUpload(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUploadResponse(upload, file), nil
+}
+
+// DeleteUpload abandons an upload and removes its chunks. Completed uploads
+// only lose the session; their file is kept.
+func (f *File) DeleteUpload(ctx context.Context, uuid string) error {
+	upload, err := f.repo.GetUpload().GetByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	return f.discardUpload(ctx, upload)
+}
+
+// completeUpload stores the assembled chunks through the same validation as
+// a simple upload.
+func (f *File) completeUpload(ctx context.Context, upload *db.FileUpload) (*dto.FileUploadResponse, error) {
+	content := f.repo.GetUpload().Open(ctx, upload)
+	defer content.Close()
+
+	var fileRecord *db.File
+	var keys []string
+
+	err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		fileRecord, keys, err = f.repo.GetFile().Upload(ctx, tx, content, upload.UploadLength, upload.FileName, upload.UploadedBy, upload.Purpose)
+		if err != nil {
+			return err
+		}
+		return f.repo.GetUpload().Complete(ctx, tx, upload.UUID, fileRecord.UUID)
+	})
+
+	if err != nil {
+		f.discardObjects(ctx, keys)
+		f.discardIfInvalid(ctx, upload, err)
+		return nil, err
+	}
+
+	// The file has its own copy now
+	f.repo.GetUpload().DeleteChunks(ctx, upload.ChunkKeys)
+
+	upload.FileUUID = &fileRecord.UUID
+	return f.toResponse(ctx, fileRecord, fileRecord.Variants)
+}
+
+// CleanupUploads removes the uploads that have seen no chunk for
+// UploadExpiry, abandoned or completed, along with their chunks. It returns
+// how many were removed.
+func (f *File) CleanupUploads(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		var uploads []*db.FileUpload
+		err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+			var err error
+			uploads, err = f.repo.GetUpload().DeleteStale(ctx, tx, config.UploadExpiry, cleanupBatchSize)
+			return err
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		for _, upload := range uploads {
+			f.repo.GetUpload().DeleteChunks(ctx, upload.ChunkKeys)
+		}
+
+		removed += len(uploads)
+		if len(uploads) < cleanupBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// discardIfInvalid ends an upload whose content can never be accepted.
+// Other failures leave it in place to be retried.
+func (f *File) discardIfInvalid(ctx context.Context, upload *db.FileUpload, err error) {
+	var appErr *constant.Error
+	if errors.As(err, &appErr) && appErr.Kind == constant.KindValidation {
+		f.discardUpload(ctx, upload)
+	}
+}
+
+func (f *File) discardUpload(ctx context.Context, upload *db.FileUpload) error {
+	err := f.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return f.repo.GetUpload().Delete(ctx, tx, upload.UUID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.repo.GetUpload().DeleteChunks(ctx, upload.ChunkKeys)
+}
+
+func toUploadResponse(upload *db.FileUpload, file *dto.FileUploadResponse) *dto.UploadResponse {
+	return &dto.UploadResponse{
+		UUID:      upload.UUID,
+		FileName:  upload.FileName,
+		Purpose:   upload.Purpose,
+		Length:    upload.UploadLength,
+		Offset:    upload.UploadOffset,
+		File:      file,
+		CreatedAt: upload.CreatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/storage"
+)
+
+var pngHead = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// fakeUpload answers the upload lookup with an upload of length bytes at
+// offset, stored in chunks chunk keys.
+func fakeUpload(purpose string, length, offset int64, chunks int) sqltest.Responder {
+	keys := make([]string, chunks)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("uploads/upload/%020d-00", i)
+	}
+
+	return func(query string, args []driver.NamedValue) *sqltest.Rows {
+		if !strings.Contains(query, "FROM file_uploads") {
+			return nil
+		}
+		return &sqltest.Rows{
+			Columns: []string{"uuid", "uploaded_by", "purpose", "file_name", "upload_length", "upload_offset", "chunk_keys", "file_uuid", "completed_at", "created_at", "updated_at"},
+			Values: [][]driver.Value{{
+				"upload", "user", purpose, "file", length, offset, "{" + strings.Join(keys, ",") + "}", nil, nil, "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z",
+			}},
+		}
+	}
+}
+
+// TestPatchUploadRejects covers the chunks refused before anything is
+// stored.
+func TestPatchUploadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		purpose string
+		length  int64
+		offset  int64
+		chunks  int
+		body    []byte
+		size    int64
+		err     error
+	}{
+		{"chunk below minimum", config.PurposePortfolio, 8 << 20, 0, 0, pngHead, config.MinChunkSize - 1, constant.ErrChunkSize},
+		{"chunk above maximum", config.PurposePortfolio, 100 << 20, 0, 0, pngHead, config.MaxChunkSize + 1, constant.ErrChunkSize},
+		{"too many chunks", config.PurposePortfolio, 500 << 20, 400 << 20, config.MaxUploadChunks, nil, config.MinChunkSize, constant.ErrTooManyChunks},
+		{"disallowed type on first chunk", config.PurposeResume, 4 << 20, 0, 0, pngHead, config.MinChunkSize, constant.ErrInvalidFileType},
+		{"image over the in-memory limit on first chunk", config.PurposePortfolio, 100 << 20, 0, 0, pngHead, config.MinChunkSize, constant.ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sqltest.Open(fakeUpload(tt.purpose, tt.length, tt.offset, tt.chunks))
+			_, err := NewFile(recorder.Registry(nil)).PatchUpload(context.Background(), "upload", tt.offset, bytes.NewReader(tt.body), tt.size)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("PatchUpload() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestCompleteUploadRollback checks that the stored file is removed when the
+// completion loses to a concurrent one and its transaction rolls back.
+func TestCompleteUploadRollback(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n")
+	dir := t.TempDir()
+	store := storage.NewLocal(dir, "")
+	chunk := fmt.Sprintf("uploads/upload/%020d-00", 0)
+	if err := store.Put(context.Background(), chunk, bytes.NewReader(pdf), int64(len(pdf)), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := fakeUpload(config.PurposeResume, int64(len(pdf)), int64(len(pdf)), 1)
+	recorder := sqltest.Open(func(query string, args []driver.NamedValue) *sqltest.Rows {
+		if strings.Contains(query, "INSERT INTO files") {
+			return &sqltest.Rows{Columns: []string{"created_at"}, Values: [][]driver.Value{{time.Now()}}}
+		}
+		// Complete matches no row, as if another request completed first
+		return upload(query, args)
+	})
+
+	_, err := NewFile(recorder.Registry(store)).PatchUpload(context.Background(), "upload", int64(len(pdf)), nil, 0)
+	if !errors.Is(err, constant.ErrUploadComplete) {
+		t.Fatalf("PatchUpload() = %v, want %v", err, constant.ErrUploadComplete)
+	}
+	if recorder.Rollbacks() != 1 {
+		t.Errorf("rollbacks = %d, want 1", recorder.Rollbacks())
+	}
+
+	documents, err := os.ReadDir(filepath.Join(dir, "documents"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 0 {
+		t.Errorf("stored documents = %d, want 0", len(documents))
+	}
+}
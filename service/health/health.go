@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/migration"
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/HPNV/growlink-backend/repository"
+	"github.com/HPNV/growlink-backend/storage"
+)
+
+const (
+	// checkTimeout bounds each readiness check so a hung dependency fails
+	// the probe instead of stalling it.
+	checkTimeout = 2 * time.Second
+	// probeKey is written and removed again by the storage check.
+	probeKey = ".readyz"
+	// storageCheckTTL is how long a storage check result is reused, so
+	// frequent probes do not turn into a stream of writes.
+	storageCheckTTL = 30 * time.Second
+)
+
+type IHealth interface {
+	Ready(ctx context.Context) *dto.ReadinessResponse
+	Version() *dto.VersionResponse
+}
+
+type Health struct {
+	repo     repository.IRegistry
+	migrator *migration.Migrator
+	storage  storage.IStorage
+
+	// mu guards the cached storage check result.
+	mu            sync.Mutex
+	storageAt     time.Time
+	storageResult error
+}
+
+func NewHealth(repo repository.IRegistry, migrations fs.FS, storage storage.IStorage) IHealth {
+	return &Health{
+		repo:     repo,
+		migrator: migration.NewMigrator(repo.GetDB(), migrations),
+		storage:  storage,
+	}
+}
+
+// Ready runs every readiness check and reports the service ready only if
+// all of them pass. The response is served to anyone, so it only says which
+// checks failed; the reasons are logged.
+func (h *Health) Ready(ctx context.Context) *dto.ReadinessResponse {
+	checks := map[string]func(context.Context) error{
+		"database":   h.checkDatabase,
+		"migrations": h.checkMigrations,
+		"storage":    h.checkStorage,
+	}
+
+	response := &dto.ReadinessResponse{
+		Status: dto.HealthStatusReady,
+		Checks: make(map[string]string, len(checks)),
+	}
+
+	for name, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check(checkCtx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Readiness check %s failed: %v", name, err)
+			response.Status = dto.HealthStatusNotReady
+			response.Checks[name] = dto.HealthStatusFailed
+			continue
+		}
+		response.Checks[name] = dto.HealthStatusOK
+	}
+
+	return response
+}
+
+func (h *Health) Version() *dto.VersionResponse {
+	return &dto.VersionResponse{
+		Version:   config.Version,
+		Commit:    config.Commit,
+		BuildTime: config.BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+func (h *Health) checkDatabase(ctx context.Context) error {
+	return h.repo.GetDB().PingContext(ctx)
+}
+
+func (h *Health) checkMigrations(ctx context.Context) error {
+	pending, err := h.migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkStorage confirms uploads can be stored by writing and removing a
+// small probe object. The outcome is reused for storageCheckTTL.
+func (h *Health) checkStorage(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.storageAt.IsZero() && time.Since(h.storageAt) < storageCheckTTL {
+		return h.storageResult
+	}
+
+	err := h.storage.Put(ctx, probeKey, strings.NewReader("ok"), 2, "text/plain")
+	if err == nil {
+		err = h.storage.Delete(ctx, probeKey)
+	}
+
+	h.storageAt, h.storageResult = time.Now(), err
+	return err
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/model/dto"
+)
+
+// failingStorage refuses every write and counts the attempts.
+type failingStorage struct {
+	puts int
+}
+
+func (s *failingStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	s.puts++
+	return errors.New("bucket growlink-private: access denied for key AKIA123")
+}
+
+func (s *failingStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, errors.New("unused")
+}
+
+func (s *failingStorage) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func (s *failingStorage) Presign(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", errors.New("unused")
+}
+
+func TestReadyHidesFailureDetails(t *testing.T) {
+	recorder := sqltest.Open(func(query string, args []driver.NamedValue) *sqltest.Rows { return nil })
+	migrations := fstest.MapFS{"1_secret_table.sql": {Data: []byte("SELECT 1;")}}
+	store := &failingStorage{}
+
+	health := NewHealth(recorder.Registry(store), migrations, store)
+
+	for i := 0; i < 3; i++ {
+		response := health.Ready(context.Background())
+
+		if response.Status != dto.HealthStatusNotReady {
+			t.Errorf("Status = %q, want %q", response.Status, dto.HealthStatusNotReady)
+		}
+		want := map[string]string{
+			"database":   dto.HealthStatusOK,
+			"migrations": dto.HealthStatusFailed,
+			"storage":    dto.HealthStatusFailed,
+		}
+		for name, value := range want {
+			if response.Checks[name] != value {
+				t.Errorf("Checks[%q] = %q, want %q", name, response.Checks[name], value)
+			}
+		}
+	}
+
+	// The storage result is reused within storageCheckTTL
+	if store.puts != 1 {
+		t.Errorf("storage was probed %d times, want 1", store.puts)
+	}
+}
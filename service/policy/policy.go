@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/repository"
+)
+
+const RoleAdmin = "admin"
+
+// Actor is the authenticated caller as populated by the auth middleware.
+type Actor struct {
+	UserUUID     string
+	Role         string
+	StudentUUID  string
+	BusinessUUID string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == RoleAdmin
+}
+
+type IPolicy interface {
+	RequireAdmin(ctx context.Context, actor Actor) error
+	CanManageBusiness(ctx context.Context, actor Actor, businessUUID string) error
+	CanManageStudent(ctx context.Context, actor Actor, studentUUID string) error
+	CanManageProject(ctx context.Context, actor Actor, projectUUID string) error
+	CanManageFile(ctx context.Context, actor Actor, fileUUID string) error
+	CanManageUpload(ctx context.Context, actor Actor, uploadUUID string) error
+}
+
+// Policy answers whether an actor may act on an entity. Entities that do not
+// exist are reported as not found and lookup failures are passed through, so
+// only a real ownership mismatch is forbidden.
+type Policy struct {
+	repo repository.IRegistry
+}
+
+func NewPolicy(repo repository.IRegistry) IPolicy {
+	return &Policy{
+		repo: repo,
+	}
+}
+
+func (p *Policy) RequireAdmin(ctx context.Context, actor Actor) error {
+	if !actor.IsAdmin() {
+		return constant.ErrForbidden
+	}
+	return nil
+}
+
+func (p *Policy) CanManageBusiness(ctx context.Context, actor Actor, businessUUID string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	if actor.BusinessUUID == "" || actor.BusinessUUID != businessUUID {
+		return constant.ErrForbidden
+	}
+	return nil
+}
+
+func (p *Policy) CanManageStudent(ctx context.Context, actor Actor, studentUUID string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	if actor.StudentUUID == "" || actor.StudentUUID != studentUUID {
+		return constant.ErrForbidden
+	}
+	return nil
+}
+
+func (p *Policy) CanManageProject(ctx context.Context, actor Actor, projectUUID string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	if actor.BusinessUUID == "" {
+		return constant.ErrForbidden
+	}
+
+	project, err := p.repo.GetProject().GetByUUID(ctx, projectUUID)
+	if err != nil {
+		return err
+	}
+	if project.CreatedBy != actor.BusinessUUID {
+		return constant.ErrForbidden
+	}
+	return nil
+}
+
+func (p *Policy) CanManageFile(ctx context.Context, actor Actor, fileUUID string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	file, err := p.repo.GetFile().GetByUUID(ctx, fileUUID)
+	if err != nil {
+		return err
+	}
+	if file.UploadedBy != actor.UserUUID {
+		return constant.ErrForbidden
+	}
+	return nil
+}
+
+// CanManageUpload only lets the uploader resume or abandon an upload; unlike
+// files there is no admin override, since nobody else has the content.
+func (p *Policy) CanManageUpload(ctx context.Context, actor Actor, uploadUUID string) error {
+	upload, err := p.repo.GetUpload().GetByUUID(ctx, uploadUUID)
+	if err != nil {
+		return err
+	}
+	if upload.UploadedBy != actor.UserUUID {
+		return constant.ErrForbidden
+	}
+	return nil
+}
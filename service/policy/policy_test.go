@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+)
+
+var errLookup = errors.New("connection reset")
+
+// fakeOwners answers the project, file and upload lookups. Every entity is
+// owned by "owner"; "missing" does not exist and "broken" fails to load.
+func fakeOwners(query string, args []driver.NamedValue) *sqltest.Rows {
+	switch args[0].Value {
+	case "missing":
+		return nil
+	case "broken":
+		return &sqltest.Rows{Err: errLookup}
+	}
+
+	column := "uploaded_by"
+	if strings.Contains(query, "FROM projects") {
+		column = "created_by"
+	}
+	return &sqltest.Rows{Columns: []string{"uuid", column}, Values: [][]driver.Value{{args[0].Value, "owner"}}}
+}
+
+func TestPolicy(t *testing.T) {
+	admin := Actor{UserUUID: "admin", Role: RoleAdmin}
+	owner := Actor{UserUUID: "owner", Role: "business", BusinessUUID: "owner", StudentUUID: "owner"}
+	stranger := Actor{UserUUID: "stranger", Role: "business", BusinessUUID: "stranger", StudentUUID: "stranger"}
+	anonymous := Actor{UserUUID: "owner", Role: "business"}
+
+	notFound := func(entity string) error { return constant.NotFound(entity+"_not_found", "") }
+
+	tests := []struct {
+		name   string
+		check  func(p IPolicy, actor Actor, uuid string) error
+		actor  Actor
+		entity string
+		err    error
+	}{
+		{"admin required", requireAdmin, owner, "", constant.ErrForbidden},
+		{"admin", requireAdmin, admin, "", nil},
+
+		{"business admin override", canManageBusiness, admin, "owner", nil},
+		{"business owner", canManageBusiness, owner, "owner", nil},
+		{"business mismatch", canManageBusiness, stranger, "owner", constant.ErrForbidden},
+		{"business without profile", canManageBusiness, anonymous, "", constant.ErrForbidden},
+
+		{"student admin override", canManageStudent, admin, "owner", nil},
+		{"student owner", canManageStudent, owner, "owner", nil},
+		{"student mismatch", canManageStudent, stranger, "owner", constant.ErrForbidden},
+		{"student without profile", canManageStudent, anonymous, "", constant.ErrForbidden},
+
+		{"project admin override", canManageProject, admin, "missing", nil},
+		{"project owner", canManageProject, owner, "project", nil},
+		{"project mismatch", canManageProject, stranger, "project", constant.ErrForbidden},
+		{"project without business", canManageProject, anonymous, "project", constant.ErrForbidden},
+		{"project not found", canManageProject, owner, "missing", notFound("project")},
+		{"project lookup failure", canManageProject, owner, "broken", errLookup},
+
+		{"file admin override", canManageFile, admin, "missing", nil},
+		{"file owner", canManageFile, owner, "file", nil},
+		{"file mismatch", canManageFile, stranger, "file", constant.ErrForbidden},
+		{"file not found", canManageFile, owner, "missing", notFound("file")},
+		{"file lookup failure", canManageFile, owner, "broken", errLookup},
+
+		{"upload owner", canManageUpload, owner, "upload", nil},
+		{"upload mismatch", canManageUpload, stranger, "upload", constant.ErrForbidden},
+		{"upload has no admin override", canManageUpload, admin, "upload", constant.ErrForbidden},
+		{"upload not found", canManageUpload, owner, "missing", notFound("upload")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sqltest.Open(fakeOwners)
+			err := tt.check(NewPolicy(recorder.Registry(nil)), tt.actor, tt.entity)
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func requireAdmin(p IPolicy, actor Actor, _ string) error {
+	return p.RequireAdmin(context.Background(), actor)
+}
+
+func canManageBusiness(p IPolicy, actor Actor, uuid string) error {
+	return p.CanManageBusiness(context.Background(), actor, uuid)
+}
+
+func canManageStudent(p IPolicy, actor Actor, uuid string) error {
+	return p.CanManageStudent(context.Background(), actor, uuid)
+}
+
+func canManageProject(p IPolicy, actor Actor, uuid string) error {
+	return p.CanManageProject(context.Background(), actor, uuid)
+}
+
+func canManageFile(p IPolicy, actor Actor, uuid string) error {
+	return p.CanManageFile(context.Background(), actor, uuid)
+}
+
+func canManageUpload(p IPolicy, actor Actor, uuid string) error {
+	return p.CanManageUpload(context.Background(), actor, uuid)
+}
@@ -0,0 +1,212 @@
+package project
+
+import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	ApplicationPending     = "pending"
+	ApplicationShortlisted = "shortlisted"
+	ApplicationAccepted    = "accepted"
+	ApplicationRejected    = "rejected"
+	ApplicationWithdrawn   = "withdrawn"
+	// ApplicationRemoved is set on the accepted application of a student
+	// the owner removes from the project; it is never requested directly.
+	ApplicationRemoved = "removed"
+)
+
+// applicationTransitions lists, for each status, the statuses it may move to.
+// Accepted, rejected, withdrawn and removed applications are final.
+var applicationTransitions = map[string][]string{
+	ApplicationPending:     {ApplicationShortlisted, ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationShortlisted: {ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn},
+}
+
+func canTransitionApplication(from, to string) bool {
+	for _, next := range applicationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Project) Apply(ctx context.Context, projectUUID, studentUUID string, req *dto.ApplicationRequest) (*dto.ApplicationResponse, error) {
+	project, err := p.repo.GetProject().GetByUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Status != StatusOpen {
+		return nil, constant.ErrProjectNotOpen
+	}
+
+	student, err := p.repo.GetStudent().GetByUUID(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fileUUID := range req.AttachmentUUIDs {
+		file, err := p.repo.GetFile().GetByUUID(ctx, fileUUID)
+		if err != nil || file.UploadedBy != student.UserUUID {
+			return nil, constant.ErrInvalidAttachment
+		}
+	}
+
+	application := &db.Application{
+		ProjectUUID:     projectUUID,
+		StudentUUID:     studentUUID,
+		CoverLetter:     req.CoverLetter,
+		AttachmentUUIDs: pq.StringArray(req.AttachmentUUIDs),
+	}
+	if application.AttachmentUUIDs == nil {
+		application.AttachmentUUIDs = pq.StringArray{}
+	}
+
+	err = p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return p.repo.GetApplication().Create(ctx, tx, application)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toApplicationResponse(application), nil
+}
+
+func (p *Project) Withdraw(ctx context.Context, projectUUID, applicationUUID, studentUUID string) (*dto.ApplicationResponse, error) {
+	application, err := p.getProjectApplication(ctx, projectUUID, applicationUUID)
+	if err != nil {
+		return nil, err
+	}
+	if application.StudentUUID != studentUUID {
+		return nil, constant.ErrForbidden
+	}
+
+	return p.transitionApplication(ctx, application, ApplicationWithdrawn)
+}
+
+func (p *Project) Shortlist(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error) {
+	application, err := p.getProjectApplication(ctx, projectUUID, applicationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.transitionApplication(ctx, application, ApplicationShortlisted)
+}
+
+func (p *Project) Accept(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error) {
+	application, err := p.getProjectApplication(ctx, projectUUID, applicationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := p.repo.GetProject().GetByUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Status != StatusOpen && project.Status != StatusInProgress {
+		return nil, constant.ErrProjectNotOpen
+	}
+
+	return p.transitionApplication(ctx, application, ApplicationAccepted)
+}
+
+func (p *Project) Reject(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error) {
+	application, err := p.getProjectApplication(ctx, projectUUID, applicationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.transitionApplication(ctx, application, ApplicationRejected)
+}
+
+func (p *Project) GetApplications(ctx context.Context, projectUUID string) ([]*dto.ApplicationResponse, error) {
+	applications, err := p.repo.GetApplication().GetByProjectUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*dto.ApplicationResponse
+	for _, application := range applications {
+		responses = append(responses, toApplicationResponse(application))
+	}
+
+	return responses, nil
+}
+
+func (p *Project) GetStudentApplications(ctx context.Context, studentUUID string) ([]*dto.ApplicationResponse, error) {
+	applications, err := p.repo.GetApplication().GetByStudentUUID(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*dto.ApplicationResponse
+	for _, application := range applications {
+		responses = append(responses, toApplicationResponse(application))
+	}
+
+	return responses, nil
+}
+
+// getProjectApplication loads an application and makes sure it belongs to the
+// given project, so a project owner cannot act on another project's applicants.
+func (p *Project) getProjectApplication(ctx context.Context, projectUUID, applicationUUID string) (*db.Application, error) {
+	application, err := p.repo.GetApplication().GetByUUID(ctx, applicationUUID)
+	if err != nil {
+		return nil, err
+	}
+	// Applications of other projects are reported as missing, not forbidden,
+	// so their existence is not revealed
+	if application.ProjectUUID != projectUUID {
+		return nil, constant.NotFound("application_not_found", "application not found")
+	}
+	return application, nil
+}
+
+func (p *Project) transitionApplication(ctx context.Context, application *db.Application, status string) (*dto.ApplicationResponse, error) {
+	from := application.Status
+	if !canTransitionApplication(from, status) {
+		return nil, constant.InvalidTransition("application", from, status)
+	}
+	application.Status = status
+
+	err := p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		if err := p.repo.GetApplication().UpdateStatus(ctx, tx, application, from); err != nil {
+			return err
+		}
+
+		// Accepting is the only way a student becomes a project member.
+		if status == ApplicationAccepted {
+			return p.repo.GetProject().AddStudent(ctx, tx, application.ProjectUUID, application.StudentUUID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toApplicationResponse(application), nil
+}
+
+func toApplicationResponse(application *db.Application) *dto.ApplicationResponse {
+	attachments := []string(application.AttachmentUUIDs)
+	if attachments == nil {
+		attachments = []string{}
+	}
+
+	return &dto.ApplicationResponse{
+		UUID:            application.UUID,
+		ProjectUUID:     application.ProjectUUID,
+		StudentUUID:     application.StudentUUID,
+		Status:          application.Status,
+		CoverLetter:     application.CoverLetter,
+		AttachmentUUIDs: attachments,
+		CreatedAt:       application.CreatedAt,
+		UpdatedAt:       application.UpdatedAt,
+	}
+}
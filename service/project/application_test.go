@@ -0,0 +1,174 @@
+package project
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+)
+
+var applicationStatuses = []string{ApplicationPending, ApplicationShortlisted, ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn, ApplicationRemoved}
+
+func TestCanTransitionApplication(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{ApplicationPending, ApplicationShortlisted}:   true,
+		{ApplicationPending, ApplicationAccepted}:      true,
+		{ApplicationPending, ApplicationRejected}:      true,
+		{ApplicationPending, ApplicationWithdrawn}:     true,
+		{ApplicationShortlisted, ApplicationAccepted}:  true,
+		{ApplicationShortlisted, ApplicationRejected}:  true,
+		{ApplicationShortlisted, ApplicationWithdrawn}: true,
+	}
+
+	for _, from := range applicationStatuses {
+		for _, to := range applicationStatuses {
+			want := allowed[[2]string{from, to}]
+			if got := canTransitionApplication(from, to); got != want {
+				t.Errorf("canTransitionApplication(%q, %q) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+// applicationFixture is the stored state the application queries see.
+type applicationFixture struct {
+	applicationProject string
+	applicationStatus  string
+	projectStatus      string
+	// lostRace makes the guarded status update match no row, as when the
+	// application changed after it was read.
+	lostRace bool
+}
+
+func (f applicationFixture) respond(query string, args []driver.NamedValue) *sqltest.Rows {
+	switch {
+	case strings.Contains(query, "UPDATE project_applications"):
+		if f.lostRace || args[2].Value != f.applicationStatus {
+			return nil
+		}
+		return &sqltest.Rows{Columns: []string{"updated_at"}, Values: [][]driver.Value{{"2025-01-02T00:00:00Z"}}}
+
+	case strings.Contains(query, "FROM project_applications WHERE uuid"):
+		return &sqltest.Rows{
+			Columns: []string{"uuid", "project_uuid", "student_uuid", "status", "cover_letter", "attachment_uuids", "created_at", "updated_at"},
+			Values:  [][]driver.Value{{"application", f.applicationProject, "student", f.applicationStatus, "", "{}", "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z"}},
+		}
+
+	case strings.Contains(query, "FROM projects WHERE uuid"):
+		return &sqltest.Rows{
+			Columns: []string{"uuid", "name", "description", "status", "duration", "timeline", "deliverables", "created_by", "created_at"},
+			Values:  [][]driver.Value{{"project", "Project", "", f.projectStatus, int64(30), "", "", "business", "2025-01-01T00:00:00Z"}},
+		}
+
+	case strings.Contains(query, "INSERT INTO student_projects"):
+		return &sqltest.Rows{Affected: 1}
+	}
+	return nil
+}
+
+func TestApplicationTransitions(t *testing.T) {
+	shortlist := func(s IProject) error {
+		_, err := s.Shortlist(context.Background(), "project", "application")
+		return err
+	}
+	accept := func(s IProject) error {
+		_, err := s.Accept(context.Background(), "project", "application")
+		return err
+	}
+	withdrawAs := func(student string) func(s IProject) error {
+		return func(s IProject) error {
+			_, err := s.Withdraw(context.Background(), "project", "application", student)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name      string
+		fixture   applicationFixture
+		act       func(s IProject) error
+		err       error
+		committed bool
+		member    bool
+	}{
+		{
+			name:      "accept adds the student to the project",
+			fixture:   applicationFixture{"project", ApplicationShortlisted, StatusOpen, false},
+			act:       accept,
+			committed: true,
+			member:    true,
+		},
+		{
+			name:      "shortlist only changes the status",
+			fixture:   applicationFixture{"project", ApplicationPending, StatusOpen, false},
+			act:       shortlist,
+			committed: true,
+		},
+		{
+			name:      "withdraw by the applicant",
+			fixture:   applicationFixture{"project", ApplicationPending, StatusOpen, false},
+			act:       withdrawAs("student"),
+			committed: true,
+		},
+		{
+			name:    "withdraw by another student",
+			fixture: applicationFixture{"project", ApplicationPending, StatusOpen, false},
+			act:     withdrawAs("someone-else"),
+			err:     constant.ErrForbidden,
+		},
+		{
+			name:    "final status cannot change",
+			fixture: applicationFixture{"project", ApplicationRejected, StatusOpen, false},
+			act:     accept,
+			err:     constant.ErrInvalidTransition,
+		},
+		{
+			name:    "accept on a finished project",
+			fixture: applicationFixture{"project", ApplicationPending, StatusCompleted, false},
+			act:     accept,
+			err:     constant.ErrProjectNotOpen,
+		},
+		{
+			name:    "concurrent change wins",
+			fixture: applicationFixture{"project", ApplicationPending, StatusOpen, true},
+			act:     accept,
+			err:     constant.ErrInvalidTransition,
+		},
+		{
+			name:    "application of another project",
+			fixture: applicationFixture{"other-project", ApplicationPending, StatusOpen, false},
+			act:     accept,
+			err:     constant.NotFound("application_not_found", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sqltest.Open(tt.fixture.respond)
+			err := tt.act(NewProject(recorder.Registry(nil)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+
+			var member bool
+			for _, call := range recorder.Calls() {
+				if strings.Contains(call.Query, "INSERT INTO student_projects") {
+					member = true
+					if call.Args[0] != "student" || call.Args[1] != "project" {
+						t.Errorf("student_projects row = %v, want [student project]", call.Args)
+					}
+				}
+			}
+
+			if committed := recorder.Commits() == 1; committed != tt.committed {
+				t.Errorf("committed = %v, want %v", committed, tt.committed)
+			}
+			if member != tt.member {
+				t.Errorf("student added = %v, want %v", member, tt.member)
+			}
+		})
+	}
+}
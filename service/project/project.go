@@ -1,6 +1,11 @@
 package project
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/helper"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
@@ -8,19 +13,26 @@ import (
 )
 
 type IProject interface {
-	Create(businessUUID string, req *dto.ProjectRequest) (*dto.ProjectResponse, error)
-	GetByUUID(uuid string) (*dto.ProjectResponse, error)
-	Update(uuid string, req *dto.ProjectUpdateRequest) (*dto.ProjectResponse, error)
-	Delete(uuid string) error
-	GetAll() ([]*dto.ProjectResponse, error)
-	GetAllList(req *dto.ProjectListRequest) (*dto.ProjectListResponse, error)
-	GetByBusinessUUID(businessUUID string) ([]*dto.ProjectResponse, error)
-	AddSkill(projectUUID, skillName string) error
-	RemoveSkill(projectUUID, skillName string) error
-	GetSkills(projectUUID string) ([]*dto.SkillResponse, error)
-	AddStudent(projectUUID, studentUUID string) error
-	RemoveStudent(projectUUID, studentUUID string) error
-	GetStudents(projectUUID string) ([]*dto.StudentResponse, error)
+	Create(ctx context.Context, businessUUID, actorUUID string, req *dto.ProjectRequest) (*dto.ProjectResponse, error)
+	GetByUUID(ctx context.Context, uuid string) (*dto.ProjectResponse, error)
+	Update(ctx context.Context, uuid, actorUUID string, req *dto.ProjectUpdateRequest) (*dto.ProjectResponse, error)
+	Delete(ctx context.Context, uuid string) error
+	GetAll(ctx context.Context) ([]*dto.ProjectResponse, error)
+	GetAllList(ctx context.Context, req *dto.ProjectListRequest) (*dto.ProjectListResponse, error)
+	GetByBusinessUUID(ctx context.Context, businessUUID string) ([]*dto.ProjectResponse, error)
+	AddSkill(ctx context.Context, projectUUID, skillName string) error
+	RemoveSkill(ctx context.Context, projectUUID, skillName string) error
+	GetSkills(ctx context.Context, projectUUID string) ([]*dto.SkillResponse, error)
+	RemoveStudent(ctx context.Context, projectUUID, studentUUID string) error
+	GetStudents(ctx context.Context, projectUUID string) ([]*dto.StudentResponse, error)
+	GetStatusHistory(ctx context.Context, projectUUID string) ([]*dto.ProjectStatusHistoryResponse, error)
+	Apply(ctx context.Context, projectUUID, studentUUID string, req *dto.ApplicationRequest) (*dto.ApplicationResponse, error)
+	Withdraw(ctx context.Context, projectUUID, applicationUUID, studentUUID string) (*dto.ApplicationResponse, error)
+	Shortlist(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error)
+	Accept(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error)
+	Reject(ctx context.Context, projectUUID, applicationUUID string) (*dto.ApplicationResponse, error)
+	GetApplications(ctx context.Context, projectUUID string) ([]*dto.ApplicationResponse, error)
+	GetStudentApplications(ctx context.Context, studentUUID string) ([]*dto.ApplicationResponse, error)
 }
 
 type Project struct {
@@ -33,29 +45,38 @@ func NewProject(repo repository.IRegistry) IProject {
 	}
 }
 
-func (p *Project) Create(businessUUID string, req *dto.ProjectRequest) (*dto.ProjectResponse, error) {
+func (p *Project) Create(ctx context.Context, businessUUID, actorUUID string, req *dto.ProjectRequest) (*dto.ProjectResponse, error) {
+	status := req.Status
+	if status == "" {
+		status = StatusOpen
+	}
+
 	project := &db.Project{
 		Name:         req.Name,
 		Description:  req.Description,
 		Duration:     req.Duration,
 		Timeline:     req.Timeline,
 		Deliverables: req.Deliverables,
-		Status:       "open",
+		Status:       status,
 		CreatedBy:    businessUUID,
 	}
 
-	err := p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		if err := p.repo.GetProject().Create(tx, project); err != nil {
+	err := p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		if err := p.repo.GetProject().Create(ctx, tx, project); err != nil {
+			return err
+		}
+
+		if err := p.recordStatus(ctx, tx, project.UUID, nil, project.Status, actorUUID); err != nil {
 			return err
 		}
 
 		for _, skillName := range req.Skills {
 			// Get skill by name to get its UUID
-			skill, err := p.repo.GetSkill().GetByName(skillName)
+			skill, err := p.repo.GetSkill().GetByName(ctx, skillName)
 			if err != nil {
 				return err
 			}
-			if err := p.repo.GetProject().AddSkill(tx, project.UUID, skill.UUID); err != nil {
+			if err := p.repo.GetProject().AddSkill(ctx, tx, project.UUID, skill.UUID); err != nil {
 				return err
 			}
 		}
@@ -81,13 +102,13 @@ func (p *Project) Create(businessUUID string, req *dto.ProjectRequest) (*dto.Pro
 	}, nil
 }
 
-func (p *Project) GetByUUID(uuid string) (*dto.ProjectResponse, error) {
-	project, err := p.repo.GetProject().GetByUUID(uuid)
+func (p *Project) GetByUUID(ctx context.Context, uuid string) (*dto.ProjectResponse, error) {
+	project, err := p.repo.GetProject().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
-	skills, err := p.repo.GetSkill().GetByProjectUUID(project.UUID)
+	skills, err := p.repo.GetSkill().GetByProjectUUID(ctx, project.UUID)
 	if err != nil {
 		return nil, err
 	}
@@ -111,13 +132,20 @@ func (p *Project) GetByUUID(uuid string) (*dto.ProjectResponse, error) {
 	}, nil
 }
 
-func (p *Project) Update(uuid string, req *dto.ProjectUpdateRequest) (*dto.ProjectResponse, error) {
+func (p *Project) Update(ctx context.Context, uuid, actorUUID string, req *dto.ProjectUpdateRequest) (*dto.ProjectResponse, error) {
 	// First get existing project
-	existing, err := p.repo.GetProject().GetByUUID(uuid)
+	existing, err := p.repo.GetProject().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
+	previousStatus := existing.Status
+	if req.Status != "" && req.Status != previousStatus {
+		if err := validateStatusTransition(previousStatus, req.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields if provided
 	if req.Name != "" {
 		existing.Name = req.Name
@@ -138,8 +166,15 @@ func (p *Project) Update(uuid string, req *dto.ProjectUpdateRequest) (*dto.Proje
 		existing.Deliverables = req.Deliverables
 	}
 
-	err = p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().Update(tx, existing)
+	err = p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		if err := p.repo.GetProject().Update(ctx, tx, existing, previousStatus); err != nil {
+			return err
+		}
+
+		if existing.Status != previousStatus {
+			return p.recordStatus(ctx, tx, existing.UUID, &previousStatus, existing.Status, actorUUID)
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -147,7 +182,7 @@ func (p *Project) Update(uuid string, req *dto.ProjectUpdateRequest) (*dto.Proje
 	}
 
 	// Get skills for the response
-	skills, err := p.repo.GetSkill().GetByProjectUUID(existing.UUID)
+	skills, err := p.repo.GetSkill().GetByProjectUUID(ctx, existing.UUID)
 	if err != nil {
 		return nil, err
 	}
@@ -171,14 +206,14 @@ func (p *Project) Update(uuid string, req *dto.ProjectUpdateRequest) (*dto.Proje
 	}, nil
 }
 
-func (p *Project) Delete(uuid string) error {
-	return p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().Delete(tx, uuid)
+func (p *Project) Delete(ctx context.Context, uuid string) error {
+	return p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return p.repo.GetProject().Delete(ctx, tx, uuid)
 	})
 }
 
-func (p *Project) GetAll() ([]*dto.ProjectResponse, error) {
-	projects, err := p.repo.GetProject().GetAll()
+func (p *Project) GetAll(ctx context.Context) ([]*dto.ProjectResponse, error) {
+	projects, err := p.repo.GetProject().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -198,25 +233,48 @@ func (p *Project) GetAll() ([]*dto.ProjectResponse, error) {
 		})
 	}
 
-	for _, project := range responses {
-		skills, err := p.repo.GetSkill().GetByProjectUUID(project.UUID)
-		if err != nil {
-			return nil, err
-		}
-		for _, skill := range skills {
-			project.Skills = append(project.Skills, skill.Name)
-		}
+	if err := p.attachSkills(ctx, responses); err != nil {
+		return nil, err
 	}
 
 	return responses, nil
 }
 
-func (p *Project) GetAllList(req *dto.ProjectListRequest) (*dto.ProjectListResponse, error) {
-	projects, totalCount, err := p.repo.GetProject().GetAllList(req)
+func (p *Project) GetAllList(ctx context.Context, req *dto.ProjectListRequest) (*dto.ProjectListResponse, error) {
+	switch req.Sort {
+	case "", dto.ProjectSortRecent:
+	case dto.ProjectSortRelevance:
+		if req.Search == nil || *req.Search == "" {
+			return nil, constant.Validation("search_required", "sort=relevance requires a search term")
+		}
+		if req.Cursor != nil {
+			return nil, constant.Validation("unsupported_sort", "sort=relevance cannot be combined with cursor pagination")
+		}
+	default:
+		return nil, constant.Validation("invalid_sort", fmt.Sprintf("unknown sort %q", req.Sort))
+	}
+
+	if req.Cursor != nil {
+		after, err := helper.DecodeCursor(*req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		req.After = after
+	}
+
+	projects, totalCount, err := p.repo.GetProject().GetAllList(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	var nextCursor *string
+	if req.Cursor != nil && len(projects) > req.Limit {
+		projects = projects[:req.Limit]
+		last := projects[len(projects)-1]
+		cursor := helper.EncodeCursor(dto.Cursor{Key: last.CreatedAt, UUID: last.UUID})
+		nextCursor = &cursor
+	}
+
 	var responses []*dto.ProjectResponse
 	for _, project := range projects {
 		responses = append(responses, &dto.ProjectResponse{
@@ -229,17 +287,21 @@ func (p *Project) GetAllList(req *dto.ProjectListRequest) (*dto.ProjectListRespo
 			Deliverables: project.Deliverables,
 			CreatedBy:    project.CreatedBy,
 			CreatedAt:    project.CreatedAt,
+			Rank:         project.Rank,
+			Snippet:      project.Snippet,
 		})
 	}
 
-	for _, project := range responses {
-		skills, err := p.repo.GetSkill().GetByProjectUUID(project.UUID)
-		if err != nil {
-			return nil, err
-		}
-		for _, skill := range skills {
-			project.Skills = append(project.Skills, skill.Name)
-		}
+	if err := p.attachSkills(ctx, responses); err != nil {
+		return nil, err
+	}
+
+	if req.Cursor != nil {
+		return &dto.ProjectListResponse{
+			Projects:   responses,
+			Limit:      req.Limit,
+			NextCursor: nextCursor,
+		}, nil
 	}
 
 	totalPages := totalCount / req.Limit
@@ -256,8 +318,8 @@ func (p *Project) GetAllList(req *dto.ProjectListRequest) (*dto.ProjectListRespo
 	}, nil
 }
 
-func (p *Project) GetByBusinessUUID(businessUUID string) ([]*dto.ProjectResponse, error) {
-	projects, err := p.repo.GetProject().GetByBusinessUUID(businessUUID)
+func (p *Project) GetByBusinessUUID(ctx context.Context, businessUUID string) ([]*dto.ProjectResponse, error) {
+	projects, err := p.repo.GetProject().GetByBusinessUUID(ctx, businessUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -277,46 +339,39 @@ func (p *Project) GetByBusinessUUID(businessUUID string) ([]*dto.ProjectResponse
 		})
 	}
 
-	// Load skills for each project
-	for _, project := range responses {
-		skills, err := p.repo.GetSkill().GetByProjectUUID(project.UUID)
-		if err != nil {
-			return nil, err
-		}
-		for _, skill := range skills {
-			project.Skills = append(project.Skills, skill.Name)
-		}
+	if err := p.attachSkills(ctx, responses); err != nil {
+		return nil, err
 	}
 
 	return responses, nil
 }
 
-func (p *Project) AddSkill(projectUUID, skillName string) error {
+func (p *Project) AddSkill(ctx context.Context, projectUUID, skillName string) error {
 	// First, get the skill by name to get its UUID
-	skill, err := p.repo.GetSkill().GetByName(skillName)
+	skill, err := p.repo.GetSkill().GetByName(ctx, skillName)
 	if err != nil {
 		return err
 	}
 
-	return p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().AddSkill(tx, projectUUID, skill.UUID)
+	return p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return p.repo.GetProject().AddSkill(ctx, tx, projectUUID, skill.UUID)
 	})
 }
 
-func (p *Project) RemoveSkill(projectUUID, skillName string) error {
+func (p *Project) RemoveSkill(ctx context.Context, projectUUID, skillName string) error {
 	// First, get the skill by name to get its UUID
-	skill, err := p.repo.GetSkill().GetByName(skillName)
+	skill, err := p.repo.GetSkill().GetByName(ctx, skillName)
 	if err != nil {
 		return err
 	}
 
-	return p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().RemoveSkill(tx, projectUUID, skill.UUID)
+	return p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return p.repo.GetProject().RemoveSkill(ctx, tx, projectUUID, skill.UUID)
 	})
 }
 
-func (p *Project) GetSkills(projectUUID string) ([]*dto.SkillResponse, error) {
-	skills, err := p.repo.GetProject().GetSkills(projectUUID)
+func (p *Project) GetSkills(ctx context.Context, projectUUID string) ([]*dto.SkillResponse, error) {
+	skills, err := p.repo.GetProject().GetSkills(ctx, projectUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -334,20 +389,19 @@ func (p *Project) GetSkills(projectUUID string) ([]*dto.SkillResponse, error) {
 	return responses, nil
 }
 
-func (p *Project) AddStudent(projectUUID, studentUUID string) error {
-	return p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().AddStudent(tx, projectUUID, studentUUID)
-	})
-}
-
-func (p *Project) RemoveStudent(projectUUID, studentUUID string) error {
-	return p.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return p.repo.GetProject().RemoveStudent(tx, projectUUID, studentUUID)
+// RemoveStudent takes a student off the project and closes the application
+// that put them there, so they can apply again later.
+func (p *Project) RemoveStudent(ctx context.Context, projectUUID, studentUUID string) error {
+	return p.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		if err := p.repo.GetProject().RemoveStudent(ctx, tx, projectUUID, studentUUID); err != nil {
+			return err
+		}
+		return p.repo.GetApplication().MarkRemoved(ctx, tx, projectUUID, studentUUID)
 	})
 }
 
-func (p *Project) GetStudents(projectUUID string) ([]*dto.StudentResponse, error) {
-	students, err := p.repo.GetProject().GetStudents(projectUUID)
+func (p *Project) GetStudents(ctx context.Context, projectUUID string) ([]*dto.StudentResponse, error) {
+	students, err := p.repo.GetProject().GetStudents(ctx, projectUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -363,3 +417,57 @@ func (p *Project) GetStudents(projectUUID string) ([]*dto.StudentResponse, error
 
 	return responses, nil
 }
+
+func (p *Project) GetStatusHistory(ctx context.Context, projectUUID string) ([]*dto.ProjectStatusHistoryResponse, error) {
+	history, err := p.repo.GetProject().GetStatusHistory(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*dto.ProjectStatusHistoryResponse
+	for _, entry := range history {
+		responses = append(responses, &dto.ProjectStatusHistoryResponse{
+			UUID:       entry.UUID,
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			ChangedBy:  entry.ChangedBy,
+			ChangedAt:  entry.ChangedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (p *Project) recordStatus(ctx context.Context, tx *sqlx.Tx, projectUUID string, from *string, to, actorUUID string) error {
+	history := &db.ProjectStatusHistory{
+		ProjectUUID: projectUUID,
+		FromStatus:  from,
+		ToStatus:    to,
+	}
+	if actorUUID != "" {
+		history.ChangedBy = &actorUUID
+	}
+
+	return p.repo.GetProject().AddStatusHistory(ctx, tx, history)
+}
+
+// attachSkills fills in the skill names of every project with one query.
+func (p *Project) attachSkills(ctx context.Context, projects []*dto.ProjectResponse) error {
+	uuids := make([]string, 0, len(projects))
+	for _, project := range projects {
+		uuids = append(uuids, project.UUID)
+	}
+
+	skills, err := p.repo.GetSkill().GetByProjectUUIDs(ctx, uuids)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		for _, skill := range skills[project.UUID] {
+			project.Skills = append(project.Skills, skill.Name)
+		}
+	}
+
+	return nil
+}
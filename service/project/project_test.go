@@ -0,0 +1,129 @@
+package project
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/model/dto"
+)
+
+const skillsPerProject = 3
+
+// fakeProjects answers the listing queries with n projects, each with
+// skillsPerProject skills.
+func fakeProjects(n int) sqltest.Responder {
+	return func(query string, args []driver.NamedValue) *sqltest.Rows {
+		switch {
+		case strings.Contains(query, "COUNT(*)"):
+			return &sqltest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(n)}}}
+
+		case strings.Contains(query, "JOIN project_skills ps"):
+			rows := &sqltest.Rows{Columns: []string{"owner_uuid", "uuid", "name", "description", "created_at"}}
+			for i := 0; i < n; i++ {
+				for j := 0; j < skillsPerProject; j++ {
+					rows.Values = append(rows.Values, []driver.Value{
+						fmt.Sprintf("project-%d", i), fmt.Sprintf("skill-%d", j), fmt.Sprintf("Skill %d", j), "", "2025-01-01T00:00:00Z",
+					})
+				}
+			}
+			return rows
+
+		case strings.Contains(query, "FROM projects"):
+			rows := &sqltest.Rows{Columns: []string{"uuid", "name", "description", "status", "duration", "timeline", "deliverables", "created_by", "created_at"}}
+			for i := 0; i < n; i++ {
+				rows.Values = append(rows.Values, []driver.Value{
+					fmt.Sprintf("project-%d", i), "Project", "", "open", int64(30), "", "", "business", "2025-01-01T00:00:00Z",
+				})
+			}
+			return rows
+		}
+		return nil
+	}
+}
+
+func newService(n int) (IProject, *sqltest.Recorder) {
+	recorder := sqltest.Open(fakeProjects(n))
+	return NewProject(recorder.Registry(nil)), recorder
+}
+
+// listings serves one page through each project listing.
+var listings = map[string]struct {
+	queries int
+	list    func(ctx context.Context, s IProject, n int) (int, error)
+}{
+	"GetAll": {2, func(ctx context.Context, s IProject, n int) (int, error) {
+		projects, err := s.GetAll(ctx)
+		return countSkills(projects), err
+	}},
+	"GetByBusinessUUID": {2, func(ctx context.Context, s IProject, n int) (int, error) {
+		projects, err := s.GetByBusinessUUID(ctx, "business")
+		return countSkills(projects), err
+	}},
+	"GetAllList": {3, func(ctx context.Context, s IProject, n int) (int, error) {
+		response, err := s.GetAllList(ctx, &dto.ProjectListRequest{Page: 1, Limit: n})
+		if err != nil {
+			return 0, err
+		}
+		return countSkills(response.Projects), nil
+	}},
+	"GetAllList cursor": {2, func(ctx context.Context, s IProject, n int) (int, error) {
+		cursor := ""
+		response, err := s.GetAllList(ctx, &dto.ProjectListRequest{Limit: n, Cursor: &cursor})
+		if err != nil {
+			return 0, err
+		}
+		return countSkills(response.Projects), nil
+	}},
+}
+
+func countSkills(projects []*dto.ProjectResponse) int {
+	total := 0
+	for _, project := range projects {
+		total += len(project.Skills)
+	}
+	return total
+}
+
+func TestListingQueryCount(t *testing.T) {
+	for name, listing := range listings {
+		for _, n := range []int{1, 10, 100} {
+			t.Run(fmt.Sprintf("%s/%d", name, n), func(t *testing.T) {
+				service, recorder := newService(n)
+
+				skills, err := listing.list(context.Background(), service, n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if skills != n*skillsPerProject {
+					t.Errorf("got %d skills, want %d", skills, n*skillsPerProject)
+				}
+				if got := recorder.Count(); got != listing.queries {
+					t.Errorf("ran %d queries, want %d:\n%s", got, listing.queries, strings.Join(recorder.Queries(), "\n"))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkListing(b *testing.B) {
+	const n = 100
+
+	for name, listing := range listings {
+		b.Run(name, func(b *testing.B) {
+			service, recorder := newService(n)
+			ctx := context.Background()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := listing.list(ctx, service, n); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(recorder.Count())/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package project
+
+import "github.com/HPNV/growlink-backend/constant"
+
+const (
+	StatusDraft      = "draft"
+	StatusOpen       = "open"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+	StatusArchived   = "archived"
+)
+
+// statusTransitions is the project lifecycle. Archived projects are final and
+// a completed project can never be reopened.
+var statusTransitions = map[string][]string{
+	StatusDraft:      {StatusOpen, StatusCancelled},
+	StatusOpen:       {StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusCompleted, StatusCancelled},
+	StatusCompleted:  {StatusArchived},
+	StatusCancelled:  {StatusArchived},
+}
+
+func validateStatusTransition(from, to string) error {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return constant.InvalidTransition("project", from, to)
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/model/dto"
+)
+
+var projectStatuses = []string{StatusDraft, StatusOpen, StatusInProgress, StatusCompleted, StatusCancelled, StatusArchived}
+
+func TestValidateStatusTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{StatusDraft, StatusOpen}:           true,
+		{StatusDraft, StatusCancelled}:      true,
+		{StatusOpen, StatusInProgress}:      true,
+		{StatusOpen, StatusCancelled}:       true,
+		{StatusInProgress, StatusCompleted}: true,
+		{StatusInProgress, StatusCancelled}: true,
+		{StatusCompleted, StatusArchived}:   true,
+		{StatusCancelled, StatusArchived}:   true,
+	}
+
+	for _, from := range projectStatuses {
+		for _, to := range projectStatuses {
+			err := validateStatusTransition(from, to)
+			if allowed[[2]string{from, to}] {
+				if err != nil {
+					t.Errorf("validateStatusTransition(%q, %q) = %v, want nil", from, to, err)
+				}
+			} else if !errors.Is(err, constant.ErrInvalidTransition) {
+				t.Errorf("validateStatusTransition(%q, %q) = %v, want %v", from, to, err, constant.ErrInvalidTransition)
+			}
+		}
+	}
+}
+
+// statusFixture is a project whose status changes, or is deleted, between
+// being read and being saved when raced is set.
+type statusFixture struct {
+	status  string
+	raced   bool
+	deleted bool
+}
+
+func (f statusFixture) respond(query string, args []driver.NamedValue) *sqltest.Rows {
+	switch {
+	case strings.Contains(query, "UPDATE projects"):
+		if f.raced {
+			return nil
+		}
+		return &sqltest.Rows{Affected: 1}
+
+	case strings.Contains(query, "SELECT EXISTS"):
+		return &sqltest.Rows{Columns: []string{"exists"}, Values: [][]driver.Value{{!f.deleted}}}
+
+	case strings.Contains(query, "INSERT INTO project_status_history"):
+		return &sqltest.Rows{Columns: []string{"uuid", "changed_at"}, Values: [][]driver.Value{{"history", "2025-01-02T00:00:00Z"}}}
+
+	case strings.Contains(query, "FROM projects"):
+		return &sqltest.Rows{
+			Columns: []string{"uuid", "name", "description", "status", "duration", "timeline", "deliverables", "created_by", "created_at"},
+			Values:  [][]driver.Value{{"project", "Project", "", f.status, int64(30), "", "", "business", "2025-01-01T00:00:00Z"}},
+		}
+	}
+	return nil
+}
+
+func TestUpdateStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture statusFixture
+		to      string
+		err     error
+		history bool
+	}{
+		{"allowed transition", statusFixture{status: StatusOpen}, StatusInProgress, nil, true},
+		{"same status", statusFixture{status: StatusOpen}, StatusOpen, nil, false},
+		{"disallowed transition", statusFixture{status: StatusCompleted}, StatusOpen, constant.ErrInvalidTransition, false},
+		{"status changed concurrently", statusFixture{status: StatusOpen, raced: true}, StatusInProgress, constant.ErrInvalidTransition, false},
+		{"deleted concurrently", statusFixture{status: StatusOpen, raced: true, deleted: true}, StatusInProgress, constant.NotFound("project_not_found", ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sqltest.Open(tt.fixture.respond)
+			_, err := NewProject(recorder.Registry(nil)).Update(context.Background(), "project", "user", &dto.ProjectUpdateRequest{Status: tt.to})
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("Update() = %v, want %v", err, tt.err)
+			}
+
+			history := false
+			for _, query := range recorder.Queries() {
+				if strings.Contains(query, "INSERT INTO project_status_history") {
+					history = true
+				}
+			}
+			if history != tt.history {
+				t.Errorf("recorded history = %v, want %v", history, tt.history)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package recommendation
+
+import (
+	"context"
+	"sort"
+
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/model/dto"
+	"github.com/HPNV/growlink-backend/repository"
+)
+
+// maxCandidates bounds how many candidates one request scores. The queries
+// preselect them by the plain share of skills matched, so the weighted score
+// only reorders the closest matches.
+const maxCandidates = 500
+
+type IRecommendation interface {
+	RecommendProjects(ctx context.Context, studentUUID string, limit int) ([]*dto.ProjectRecommendation, error)
+	RecommendStudents(ctx context.Context, projectUUID string, limit int) ([]*dto.StudentRecommendation, error)
+}
+
+type Recommendation struct {
+	repo repository.IRegistry
+}
+
+func NewRecommendation(repo repository.IRegistry) IRecommendation {
+	return &Recommendation{
+		repo: repo,
+	}
+}
+
+// RecommendProjects ranks the open projects that share at least one skill
+// with the student by how much of each project's skill set the student
+// covers.
+func (r *Recommendation) RecommendProjects(ctx context.Context, studentUUID string, limit int) ([]*dto.ProjectRecommendation, error) {
+	if _, err := r.repo.GetStudent().GetByUUID(ctx, studentUUID); err != nil {
+		return nil, err
+	}
+
+	studentSkills, err := r.repo.GetSkill().GetByStudentUUID(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := r.repo.GetProject().GetOpenBySharedSkills(ctx, studentUUID, maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	projectUUIDs := make([]string, 0, len(projects))
+	for _, project := range projects {
+		projectUUIDs = append(projectUUIDs, project.UUID)
+	}
+
+	projectSkills, err := r.repo.GetSkill().GetByProjectUUIDs(ctx, projectUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := r.rarity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]*dto.ProjectRecommendation, 0, len(projects))
+	for _, project := range projects {
+		skills := projectSkills[project.UUID]
+		score, breakdown := weights.score(skills, studentSkills)
+
+		recommendations = append(recommendations, &dto.ProjectRecommendation{
+			Project: &dto.ProjectResponse{
+				UUID:         project.UUID,
+				Name:         project.Name,
+				Description:  project.Description,
+				Status:       project.Status,
+				Duration:     project.Duration,
+				Timeline:     project.Timeline,
+				Deliverables: project.Deliverables,
+				Skills:       skillNames(skills),
+				CreatedBy:    project.CreatedBy,
+				CreatedAt:    project.CreatedAt,
+			},
+			Score:     score,
+			Breakdown: breakdown,
+		})
+	}
+
+	// Candidates arrive newest first among equal coverage, so a stable sort
+	// keeps newer projects ahead on equal scores.
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return better(recommendations[i].Score, recommendations[i].Breakdown, recommendations[j].Score, recommendations[j].Breakdown)
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations, nil
+}
+
+// RecommendStudents ranks the students who have at least one of the
+// project's skills by how much of the project's skill set they cover.
+func (r *Recommendation) RecommendStudents(ctx context.Context, projectUUID string, limit int) ([]*dto.StudentRecommendation, error) {
+	if _, err := r.repo.GetProject().GetByUUID(ctx, projectUUID); err != nil {
+		return nil, err
+	}
+
+	projectSkills, err := r.repo.GetSkill().GetByProjectUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := r.repo.GetStudent().GetBySharedSkills(ctx, projectUUID, maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	studentUUIDs := make([]string, 0, len(students))
+	for _, student := range students {
+		studentUUIDs = append(studentUUIDs, student.UUID)
+	}
+
+	studentSkills, err := r.repo.GetSkill().GetByStudentUUIDs(ctx, studentUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := r.rarity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]*dto.StudentRecommendation, 0, len(students))
+	for _, student := range students {
+		skills := studentSkills[student.UUID]
+		score, breakdown := weights.score(projectSkills, skills)
+
+		recommendations = append(recommendations, &dto.StudentRecommendation{
+			Student: &dto.StudentResponse{
+				UUID:       student.UUID,
+				UserUUID:   student.UserUUID,
+				University: student.University,
+			},
+			Skills:    skillNames(skills),
+			Score:     score,
+			Breakdown: breakdown,
+		})
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return better(recommendations[i].Score, recommendations[i].Breakdown, recommendations[j].Score, recommendations[j].Breakdown)
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations, nil
+}
+
+func (r *Recommendation) rarity(ctx context.Context) (*rarity, error) {
+	students, err := r.repo.GetStudent().Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := r.repo.GetSkill().GetStudentCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rarity{students: students, counts: counts}, nil
+}
+
+// better orders recommendations by score, then by the number of matched
+// skills.
+func better(scoreA float64, a *dto.ScoreBreakdown, scoreB float64, b *dto.ScoreBreakdown) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	return a.MatchedSkills > b.MatchedSkills
+}
+
+func skillNames(skills []*db.Skill) []string {
+	names := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		names = append(names, skill.Name)
+	}
+	return names
+}
@@ -0,0 +1,147 @@
+package recommendation
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	"github.com/HPNV/growlink-backend/model/db"
+)
+
+func TestScore(t *testing.T) {
+	weights := &rarity{students: 10, counts: map[string]int{"go": 9, "rust": 1}}
+
+	common, rare := weights.weight("go"), weights.weight("rust")
+	if want := math.Log(11.0/10) + 1; common != want {
+		t.Errorf("weight(go) = %v, want %v", common, want)
+	}
+	if want := math.Log(11.0/2) + 1; rare != want {
+		t.Errorf("weight(rust) = %v, want %v", rare, want)
+	}
+	if unused, want := weights.weight("zig"), math.Log(11)+1; unused != want {
+		t.Errorf("weight of a skill nobody has = %v, want %v", unused, want)
+	}
+
+	project := []*db.Skill{{UUID: "go", Name: "Go"}, {UUID: "rust", Name: "Rust"}}
+
+	rareScore, breakdown := weights.score(project, []*db.Skill{{UUID: "rust"}})
+	commonScore, _ := weights.score(project, []*db.Skill{{UUID: "go"}})
+	if rareScore <= commonScore {
+		t.Errorf("matching the rare skill scores %v, matching the common one %v, want the rare match ahead", rareScore, commonScore)
+	}
+	if want := round(rare / (common + rare)); rareScore != want {
+		t.Errorf("score = %v, want %v", rareScore, want)
+	}
+
+	if breakdown.TotalSkills != 2 || breakdown.MatchedSkills != 1 {
+		t.Errorf("matched %d of %d skills, want 1 of 2", breakdown.MatchedSkills, breakdown.TotalSkills)
+	}
+	if breakdown.MatchedWeight != round(rare) || breakdown.TotalWeight != round(common+rare) {
+		t.Errorf("weights = %v of %v, want %v of %v", breakdown.MatchedWeight, breakdown.TotalWeight, round(rare), round(common+rare))
+	}
+	if len(breakdown.Skills) != 2 || breakdown.Skills[0].Matched || !breakdown.Skills[1].Matched || breakdown.Skills[1].Weight != round(rare) {
+		t.Errorf("unexpected skill breakdown %+v %+v", breakdown.Skills[0], breakdown.Skills[1])
+	}
+
+	if score, _ := weights.score(nil, []*db.Skill{{UUID: "go"}}); score != 0 {
+		t.Errorf("project without skills scores %v, want 0", score)
+	}
+}
+
+// recommendationFixture answers the queries of RecommendProjects for a
+// student with the common skill go and the rare skill rust, out of ten
+// students. Candidates are listed newest first.
+type recommendationFixture struct {
+	candidates []string
+	skills     map[string][]string
+}
+
+func (f recommendationFixture) respond(query string, args []driver.NamedValue) *sqltest.Rows {
+	switch {
+	case strings.Contains(query, "FROM students WHERE uuid"):
+		return &sqltest.Rows{Columns: []string{"uuid", "user_uuid", "university"}, Values: [][]driver.Value{{"student", "user", ""}}}
+
+	case strings.Contains(query, "p.status = 'open'"):
+		rows := &sqltest.Rows{Columns: []string{"uuid", "name", "description", "status", "duration", "timeline", "deliverables", "created_by", "created_at"}}
+		for _, uuid := range f.candidates {
+			rows.Values = append(rows.Values, []driver.Value{uuid, uuid, "", "open", int64(30), "", "", "business", "2025-01-01T00:00:00Z"})
+		}
+		return rows
+
+	case strings.Contains(query, "ss.student_uuid = $1"):
+		return skillRows([]string{"uuid", "name", "description", "created_at"}, "", []string{"go", "rust"})
+
+	case strings.Contains(query, "ANY($1)"):
+		rows := &sqltest.Rows{Columns: []string{"owner_uuid", "uuid", "name", "description", "created_at"}}
+		for _, project := range f.candidates {
+			rows.Values = append(rows.Values, skillRows(nil, project, f.skills[project]).Values...)
+		}
+		return rows
+
+	case strings.Contains(query, "COUNT(*) FROM students"):
+		return &sqltest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(10)}}}
+
+	case strings.Contains(query, "GROUP BY skill_uuid"):
+		return &sqltest.Rows{Columns: []string{"skill_uuid", "count"}, Values: [][]driver.Value{{"go", int64(9)}, {"rust", int64(1)}, {"sql", int64(4)}}}
+	}
+	return nil
+}
+
+// skillRows lists skills named after their UUIDs, prefixed with owner when
+// it is set.
+func skillRows(columns []string, owner string, skills []string) *sqltest.Rows {
+	rows := &sqltest.Rows{Columns: columns}
+	for _, skill := range skills {
+		row := []driver.Value{skill, skill, "", "2025-01-01T00:00:00Z"}
+		if owner != "" {
+			row = append([]driver.Value{owner}, row...)
+		}
+		rows.Values = append(rows.Values, row)
+	}
+	return rows
+}
+
+func TestRecommendProjects(t *testing.T) {
+	fixture := recommendationFixture{
+		candidates: []string{"common", "rare", "rust-only", "both", "both-older"},
+		skills: map[string][]string{
+			"common":     {"go", "sql"},
+			"rare":       {"rust", "sql"},
+			"rust-only":  {"rust"},
+			"both":       {"go", "rust"},
+			"both-older": {"go", "rust"},
+		},
+	}
+
+	recorder := sqltest.Open(fixture.respond)
+	recommendations, err := NewRecommendation(recorder.Registry(nil)).RecommendProjects(context.Background(), "student", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Full matches come first, the one covering more skills ahead and the
+	// newer ahead on a tie; a rare match beats a common one, and the limit
+	// drops the last.
+	want := []string{"both", "both-older", "rust-only", "rare"}
+	if len(recommendations) != len(want) {
+		t.Fatalf("got %d recommendations, want %d", len(recommendations), len(want))
+	}
+	for i, recommendation := range recommendations {
+		if recommendation.Project.UUID != want[i] {
+			t.Errorf("recommendation %d = %q (score %v), want %q", i, recommendation.Project.UUID, recommendation.Score, want[i])
+		}
+	}
+
+	for _, call := range recorder.Calls() {
+		if strings.Contains(call.Query, "p.status = 'open'") {
+			if len(call.Args) != 2 || call.Args[1] != int64(maxCandidates) {
+				t.Errorf("candidate query args = %v, want the student and a limit of %d", call.Args, maxCandidates)
+			}
+			return
+		}
+	}
+	t.Error("candidate query was not run")
+}
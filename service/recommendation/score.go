@@ -0,0 +1,64 @@
+package recommendation
+
+import (
+	"math"
+
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/model/dto"
+)
+
+// rarity weighs skills by inverse document frequency over students, so a
+// match on a skill few students have counts for more than a common one.
+type rarity struct {
+	students int
+	counts   map[string]int
+}
+
+func (r *rarity) weight(skillUUID string) float64 {
+	return math.Log(float64(r.students+1)/float64(r.counts[skillUUID]+1)) + 1
+}
+
+// score rates how well a student's skills cover a project's skills. The
+// result is in [0, 1]; projects without skills score zero.
+func (r *rarity) score(projectSkills, studentSkills []*db.Skill) (float64, *dto.ScoreBreakdown) {
+	has := make(map[string]bool, len(studentSkills))
+	for _, skill := range studentSkills {
+		has[skill.UUID] = true
+	}
+
+	breakdown := &dto.ScoreBreakdown{
+		TotalSkills: len(projectSkills),
+		Skills:      make([]*dto.SkillScore, 0, len(projectSkills)),
+	}
+
+	for _, skill := range projectSkills {
+		weight := r.weight(skill.UUID)
+		matched := has[skill.UUID]
+
+		breakdown.TotalWeight += weight
+		if matched {
+			breakdown.MatchedWeight += weight
+			breakdown.MatchedSkills++
+		}
+
+		breakdown.Skills = append(breakdown.Skills, &dto.SkillScore{
+			Skill:   skill.Name,
+			Weight:  round(weight),
+			Matched: matched,
+		})
+	}
+
+	var score float64
+	if breakdown.TotalWeight > 0 {
+		score = breakdown.MatchedWeight / breakdown.TotalWeight
+	}
+
+	breakdown.MatchedWeight = round(breakdown.MatchedWeight)
+	breakdown.TotalWeight = round(breakdown.TotalWeight)
+
+	return round(score), breakdown
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"github.com/HPNV/growlink-backend/service/business"
 	"github.com/HPNV/growlink-backend/service/file"
+	"github.com/HPNV/growlink-backend/service/health"
+	"github.com/HPNV/growlink-backend/service/policy"
 	"github.com/HPNV/growlink-backend/service/project"
+	"github.com/HPNV/growlink-backend/service/recommendation"
 	"github.com/HPNV/growlink-backend/service/skill"
 	"github.com/HPNV/growlink-backend/service/student"
 	"github.com/HPNV/growlink-backend/service/user"
@@ -16,15 +19,21 @@ type IRegistry interface {
 	GetStudent() student.IStudent
 	GetProject() project.IProject
 	GetFile() file.IFile
+	GetPolicy() policy.IPolicy
+	GetRecommendation() recommendation.IRecommendation
+	GetHealth() health.IHealth
 }
 
 type Registry struct {
-	user     user.IUser
-	skill    skill.ISkill
-	business business.IBusiness
-	student  student.IStudent
-	project  project.IProject
-	file     file.IFile
+	user           user.IUser
+	skill          skill.ISkill
+	business       business.IBusiness
+	student        student.IStudent
+	project        project.IProject
+	file           file.IFile
+	policy         policy.IPolicy
+	recommendation recommendation.IRecommendation
+	health         health.IHealth
 }
 
 func NewRegistry(
@@ -34,14 +43,20 @@ func NewRegistry(
 	student student.IStudent,
 	project project.IProject,
 	file file.IFile,
+	policy policy.IPolicy,
+	recommendation recommendation.IRecommendation,
+	health health.IHealth,
 ) *Registry {
 	return &Registry{
-		user:     user,
-		skill:    skill,
-		business: business,
-		student:  student,
-		project:  project,
-		file:     file,
+		user:           user,
+		skill:          skill,
+		business:       business,
+		student:        student,
+		project:        project,
+		file:           file,
+		policy:         policy,
+		recommendation: recommendation,
+		health:         health,
 	}
 }
 
@@ -68,3 +83,15 @@ func (r *Registry) GetProject() project.IProject {
 func (r *Registry) GetFile() file.IFile {
 	return r.file
 }
+
+func (r *Registry) GetPolicy() policy.IPolicy {
+	return r.policy
+}
+
+func (r *Registry) GetRecommendation() recommendation.IRecommendation {
+	return r.recommendation
+}
+
+func (r *Registry) GetHealth() health.IHealth {
+	return r.health
+}
@@ -2,7 +2,10 @@ package skill
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/HPNV/growlink-backend/constant"
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
@@ -10,12 +13,18 @@ import (
 )
 
 type ISkill interface {
-	CreateSkill(name string) (string, error)
-	Create(req *dto.SkillRequest) (*dto.SkillResponse, error)
-	GetByUUID(uuid string) (*dto.SkillResponse, error)
-	Update(uuid string, req *dto.SkillRequest) (*dto.SkillResponse, error)
-	Delete(uuid string) error
-	GetAll() ([]*dto.SkillResponse, error)
+	CreateSkill(ctx context.Context, name string) (string, error)
+	Create(ctx context.Context, req *dto.SkillRequest) (*dto.SkillResponse, error)
+	GetByUUID(ctx context.Context, uuid string) (*dto.SkillResponse, error)
+	Update(ctx context.Context, uuid string, req *dto.SkillRequest) (*dto.SkillResponse, error)
+	Delete(ctx context.Context, uuid string) error
+	GetAll(ctx context.Context) ([]*dto.SkillResponse, error)
+	AddAlias(ctx context.Context, uuid string, req *dto.SkillAliasRequest) (*dto.SkillResponse, error)
+	RemoveAlias(ctx context.Context, uuid, alias string) error
+	Merge(ctx context.Context, targetUUID string, req *dto.SkillMergeRequest) (*dto.SkillResponse, error)
+	CreateCategory(ctx context.Context, req *dto.SkillCategoryRequest) (*dto.SkillCategoryResponse, error)
+	GetCategories(ctx context.Context) ([]*dto.SkillCategoryResponse, error)
+	Suggest(ctx context.Context, term string, limit int) ([]*dto.SkillSuggestionResponse, error)
 }
 
 type Skill struct {
@@ -28,11 +37,11 @@ func NewSkill(repo repository.IRegistry) ISkill {
 	}
 }
 
-func (s *Skill) CreateSkill(name string) (string, error) {
+func (s *Skill) CreateSkill(ctx context.Context, name string) (string, error) {
 	var skillID string
-	err := s.repo.WithTransaction(func(tx *sqlx.Tx) error {
+	err := s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
 		var err error
-		skillID, err = s.repo.GetSkill().CreateSkill(context.Background(), tx, name)
+		skillID, err = s.repo.GetSkill().CreateSkill(ctx, tx, name)
 		return err
 	})
 	if err != nil {
@@ -41,14 +50,19 @@ func (s *Skill) CreateSkill(name string) (string, error) {
 	return skillID, nil
 }
 
-func (s *Skill) Create(req *dto.SkillRequest) (*dto.SkillResponse, error) {
+func (s *Skill) Create(ctx context.Context, req *dto.SkillRequest) (*dto.SkillResponse, error) {
+	if err := s.ensureNameFree(ctx, req.Name, ""); err != nil {
+		return nil, err
+	}
+
 	skill := &db.Skill{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:         req.Name,
+		Description:  req.Description,
+		CategoryUUID: req.CategoryUUID,
 	}
 
-	err := s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetSkill().Create(tx, skill)
+	err := s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().Create(ctx, tx, skill)
 	})
 
 	if err != nil {
@@ -56,40 +70,53 @@ func (s *Skill) Create(req *dto.SkillRequest) (*dto.SkillResponse, error) {
 	}
 
 	return &dto.SkillResponse{
-		UUID:        skill.UUID,
-		Name:        skill.Name,
-		Description: skill.Description,
-		CreatedAt:   skill.CreatedAt,
+		UUID:         skill.UUID,
+		Name:         skill.Name,
+		Description:  skill.Description,
+		CategoryUUID: skill.CategoryUUID,
+		CreatedAt:    skill.CreatedAt,
 	}, nil
 }
 
-func (s *Skill) GetByUUID(uuid string) (*dto.SkillResponse, error) {
-	skill, err := s.repo.GetSkill().GetByUUID(uuid)
+func (s *Skill) GetByUUID(ctx context.Context, uuid string) (*dto.SkillResponse, error) {
+	skill, err := s.repo.GetSkill().GetByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := s.repo.GetSkill().GetAliases(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.SkillResponse{
-		UUID:        skill.UUID,
-		Name:        skill.Name,
-		Description: skill.Description,
-		CreatedAt:   skill.CreatedAt,
+		UUID:         skill.UUID,
+		Name:         skill.Name,
+		Description:  skill.Description,
+		CategoryUUID: skill.CategoryUUID,
+		Aliases:      aliases,
+		CreatedAt:    skill.CreatedAt,
 	}, nil
 }
 
-func (s *Skill) Update(uuid string, req *dto.SkillRequest) (*dto.SkillResponse, error) {
+func (s *Skill) Update(ctx context.Context, uuid string, req *dto.SkillRequest) (*dto.SkillResponse, error) {
 	// First get existing skill
-	existing, err := s.repo.GetSkill().GetByUUID(uuid)
+	existing, err := s.repo.GetSkill().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.ensureNameFree(ctx, req.Name, uuid); err != nil {
+		return nil, err
+	}
+
 	// Update fields
 	existing.Name = req.Name
 	existing.Description = req.Description
+	existing.CategoryUUID = req.CategoryUUID
 
-	err = s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetSkill().Update(tx, existing)
+	err = s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().Update(ctx, tx, existing)
 	})
 
 	if err != nil {
@@ -97,21 +124,22 @@ func (s *Skill) Update(uuid string, req *dto.SkillRequest) (*dto.SkillResponse,
 	}
 
 	return &dto.SkillResponse{
-		UUID:        existing.UUID,
-		Name:        existing.Name,
-		Description: existing.Description,
-		CreatedAt:   existing.CreatedAt,
+		UUID:         existing.UUID,
+		Name:         existing.Name,
+		Description:  existing.Description,
+		CategoryUUID: existing.CategoryUUID,
+		CreatedAt:    existing.CreatedAt,
 	}, nil
 }
 
-func (s *Skill) Delete(uuid string) error {
-	return s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetSkill().Delete(tx, uuid)
+func (s *Skill) Delete(ctx context.Context, uuid string) error {
+	return s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().Delete(ctx, tx, uuid)
 	})
 }
 
-func (s *Skill) GetAll() ([]*dto.SkillResponse, error) {
-	skills, err := s.repo.GetSkill().GetAll()
+func (s *Skill) GetAll(ctx context.Context) ([]*dto.SkillResponse, error) {
+	skills, err := s.repo.GetSkill().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -119,12 +147,153 @@ func (s *Skill) GetAll() ([]*dto.SkillResponse, error) {
 	var responses []*dto.SkillResponse
 	for _, skill := range skills {
 		responses = append(responses, &dto.SkillResponse{
-			UUID:        skill.UUID,
-			Name:        skill.Name,
-			Description: skill.Description,
-			CreatedAt:   skill.CreatedAt,
+			UUID:         skill.UUID,
+			Name:         skill.Name,
+			Description:  skill.Description,
+			CategoryUUID: skill.CategoryUUID,
+			CreatedAt:    skill.CreatedAt,
 		})
 	}
 
 	return responses, nil
 }
+
+// AddAlias lets the skill also be found under another name, e.g. "golang"
+// for "Go".
+func (s *Skill) AddAlias(ctx context.Context, uuid string, req *dto.SkillAliasRequest) (*dto.SkillResponse, error) {
+	if _, err := s.repo.GetSkill().GetByUUID(ctx, uuid); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNameFree(ctx, req.Alias, ""); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().AddAlias(ctx, tx, uuid, req.Alias)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByUUID(ctx, uuid)
+}
+
+func (s *Skill) RemoveAlias(ctx context.Context, uuid, alias string) error {
+	return s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().RemoveAlias(ctx, tx, uuid, alias)
+	})
+}
+
+// Merge folds a duplicate skill into the target. Everything that referenced
+// the source moves to the target and the source name becomes an alias, so
+// existing clients that still send it keep working.
+func (s *Skill) Merge(ctx context.Context, targetUUID string, req *dto.SkillMergeRequest) (*dto.SkillResponse, error) {
+	if req.SourceUUID == targetUUID {
+		return nil, constant.Validation("invalid_merge", "a skill cannot be merged into itself")
+	}
+
+	if _, err := s.repo.GetSkill().GetByUUID(ctx, targetUUID); err != nil {
+		return nil, err
+	}
+
+	source, err := s.repo.GetSkill().GetByUUID(ctx, req.SourceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		if err := s.repo.GetSkill().Merge(ctx, tx, source.UUID, targetUUID); err != nil {
+			return err
+		}
+		return s.repo.GetSkill().AddAlias(ctx, tx, targetUUID, source.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByUUID(ctx, targetUUID)
+}
+
+func (s *Skill) CreateCategory(ctx context.Context, req *dto.SkillCategoryRequest) (*dto.SkillCategoryResponse, error) {
+	if req.ParentUUID != nil {
+		if _, err := s.repo.GetSkill().GetCategoryByUUID(ctx, *req.ParentUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	category := &db.SkillCategory{
+		Name:       req.Name,
+		ParentUUID: req.ParentUUID,
+	}
+
+	err := s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetSkill().CreateCategory(ctx, tx, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SkillCategoryResponse{
+		UUID:       category.UUID,
+		Name:       category.Name,
+		ParentUUID: category.ParentUUID,
+		CreatedAt:  category.CreatedAt,
+	}, nil
+}
+
+func (s *Skill) GetCategories(ctx context.Context) ([]*dto.SkillCategoryResponse, error) {
+	categories, err := s.repo.GetSkill().GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SkillCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		responses = append(responses, &dto.SkillCategoryResponse{
+			UUID:       category.UUID,
+			Name:       category.Name,
+			ParentUUID: category.ParentUUID,
+			CreatedAt:  category.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (s *Skill) Suggest(ctx context.Context, term string, limit int) ([]*dto.SkillSuggestionResponse, error) {
+	suggestions, err := s.repo.GetSkill().Suggest(ctx, term, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SkillSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		responses = append(responses, &dto.SkillSuggestionResponse{
+			UUID:         suggestion.UUID,
+			Name:         suggestion.Name,
+			MatchedAlias: suggestion.MatchedAlias,
+			Score:        suggestion.Score,
+			ProjectCount: suggestion.ProjectCount,
+			StudentCount: suggestion.StudentCount,
+		})
+	}
+
+	return responses, nil
+}
+
+// ensureNameFree rejects a skill name or alias that already resolves to a
+// skill other than except, so names and aliases never shadow each other.
+func (s *Skill) ensureNameFree(ctx context.Context, name, except string) error {
+	existing, err := s.repo.GetSkill().GetByName(ctx, name)
+	if errors.Is(err, constant.UnknownSkill(name)) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.UUID == except {
+		return nil
+	}
+	return constant.Conflict("skill_name_taken", fmt.Sprintf("%q already refers to skill %q", name, existing.Name))
+}
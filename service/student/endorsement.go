@@ -0,0 +1,125 @@
+package student
+
+import (
+	"context"
+
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/model/db"
+	"github.com/HPNV/growlink-backend/model/dto"
+	projectService "github.com/HPNV/growlink-backend/service/project"
+	"github.com/jmoiron/sqlx"
+)
+
+// Endorse records a business vouching for one of the student's skills. The
+// business must own the project, the project must be completed, and the
+// student must have worked on it and list the skill.
+func (s *Student) Endorse(ctx context.Context, studentUUID, businessUUID string, req *dto.EndorsementRequest) (*dto.EndorsementResponse, error) {
+	project, err := s.repo.GetProject().GetByUUID(ctx, req.ProjectUUID)
+	if err != nil {
+		return nil, err
+	}
+	if project.CreatedBy != businessUUID {
+		return nil, constant.ErrForbidden
+	}
+
+	business, err := s.repo.GetBusiness().GetByUUID(ctx, businessUUID)
+	if err != nil {
+		return nil, err
+	}
+	// Only finished work can be endorsed
+	if project.Status != projectService.StatusCompleted {
+		return nil, constant.ErrProjectNotCompleted
+	}
+
+	members, err := s.repo.GetProject().GetStudents(ctx, project.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasStudent(members, studentUUID) {
+		return nil, constant.ErrNotProjectMember
+	}
+
+	skill, err := s.repo.GetSkill().GetByName(ctx, req.SkillName)
+	if err != nil {
+		return nil, err
+	}
+
+	skills, err := s.repo.GetStudent().GetSkills(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasSkill(skills, skill.UUID) {
+		return nil, constant.ErrSkillNotListed
+	}
+
+	endorsement := &db.Endorsement{
+		StudentUUID:  studentUUID,
+		SkillUUID:    skill.UUID,
+		BusinessUUID: businessUUID,
+		ProjectUUID:  project.UUID,
+		Comment:      req.Comment,
+	}
+
+	err = s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetEndorsement().Create(ctx, tx, endorsement)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endorsement.SkillName = skill.Name
+	endorsement.CompanyName = business.CompanyName
+	endorsement.ProjectName = project.Name
+
+	return toEndorsementResponse(endorsement), nil
+}
+
+func (s *Student) GetEndorsements(ctx context.Context, studentUUID string) ([]*dto.EndorsementResponse, error) {
+	if _, err := s.repo.GetStudent().GetByUUID(ctx, studentUUID); err != nil {
+		return nil, err
+	}
+
+	endorsements, err := s.repo.GetEndorsement().GetByStudentUUID(ctx, studentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.EndorsementResponse, 0, len(endorsements))
+	for _, endorsement := range endorsements {
+		responses = append(responses, toEndorsementResponse(endorsement))
+	}
+
+	return responses, nil
+}
+
+func toEndorsementResponse(endorsement *db.Endorsement) *dto.EndorsementResponse {
+	return &dto.EndorsementResponse{
+		UUID:         endorsement.UUID,
+		StudentUUID:  endorsement.StudentUUID,
+		Skill:        endorsement.SkillName,
+		BusinessUUID: endorsement.BusinessUUID,
+		CompanyName:  endorsement.CompanyName,
+		ProjectUUID:  endorsement.ProjectUUID,
+		ProjectName:  endorsement.ProjectName,
+		Comment:      endorsement.Comment,
+		CreatedAt:    endorsement.CreatedAt,
+	}
+}
+
+func hasStudent(students []*db.Student, studentUUID string) bool {
+	for _, student := range students {
+		if student.UUID == studentUUID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSkill(skills []*db.StudentSkill, skillUUID string) bool {
+	for _, skill := range skills {
+		if skill.SkillUUID == skillUUID {
+			return true
+		}
+	}
+	return false
+}
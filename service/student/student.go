@@ -1,6 +1,8 @@
 package student
 
 import (
+	"context"
+
 	"github.com/HPNV/growlink-backend/model/db"
 	"github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
@@ -8,15 +10,17 @@ import (
 )
 
 type IStudent interface {
-	Create(userUUID string, req *dto.StudentRequest) (*dto.StudentResponse, error)
-	GetByUUID(uuid string) (*dto.StudentResponse, error)
-	GetByUserUUID(userUUID string) (*dto.StudentResponse, error)
-	Update(uuid string, req *dto.StudentRequest) (*dto.StudentResponse, error)
-	Delete(uuid string) error
-	GetAll() ([]*dto.StudentResponse, error)
-	AddSkill(studentUUID, skillName string) error
-	RemoveSkill(studentUUID, skillName string) error
-	GetSkills(studentUUID string) ([]*dto.SkillResponse, error)
+	Create(ctx context.Context, userUUID string, req *dto.StudentRequest) (*dto.StudentResponse, error)
+	GetByUUID(ctx context.Context, uuid string) (*dto.StudentResponse, error)
+	GetByUserUUID(ctx context.Context, userUUID string) (*dto.StudentResponse, error)
+	Update(ctx context.Context, uuid string, req *dto.StudentRequest) (*dto.StudentResponse, error)
+	Delete(ctx context.Context, uuid string) error
+	GetAll(ctx context.Context) ([]*dto.StudentResponse, error)
+	AddSkill(ctx context.Context, studentUUID string, req *dto.StudentSkillRequest) error
+	RemoveSkill(ctx context.Context, studentUUID, skillName string) error
+	GetSkills(ctx context.Context, studentUUID string) ([]*dto.StudentSkillResponse, error)
+	Endorse(ctx context.Context, studentUUID, businessUUID string, req *dto.EndorsementRequest) (*dto.EndorsementResponse, error)
+	GetEndorsements(ctx context.Context, studentUUID string) ([]*dto.EndorsementResponse, error)
 }
 
 type Student struct {
@@ -29,14 +33,14 @@ func NewStudent(repo repository.IRegistry) IStudent {
 	}
 }
 
-func (s *Student) Create(userUUID string, req *dto.StudentRequest) (*dto.StudentResponse, error) {
+func (s *Student) Create(ctx context.Context, userUUID string, req *dto.StudentRequest) (*dto.StudentResponse, error) {
 	student := &db.Student{
 		UserUUID:   userUUID,
 		University: req.University,
 	}
 
-	err := s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetStudent().Create(tx, student)
+	err := s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetStudent().Create(ctx, tx, student)
 	})
 
 	if err != nil {
@@ -50,8 +54,8 @@ func (s *Student) Create(userUUID string, req *dto.StudentRequest) (*dto.Student
 	}, nil
 }
 
-func (s *Student) GetByUUID(uuid string) (*dto.StudentResponse, error) {
-	student, err := s.repo.GetStudent().GetByUUID(uuid)
+func (s *Student) GetByUUID(ctx context.Context, uuid string) (*dto.StudentResponse, error) {
+	student, err := s.repo.GetStudent().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -63,8 +67,8 @@ func (s *Student) GetByUUID(uuid string) (*dto.StudentResponse, error) {
 	}, nil
 }
 
-func (s *Student) GetByUserUUID(userUUID string) (*dto.StudentResponse, error) {
-	student, err := s.repo.GetStudent().GetByUserUUID(userUUID)
+func (s *Student) GetByUserUUID(ctx context.Context, userUUID string) (*dto.StudentResponse, error) {
+	student, err := s.repo.GetStudent().GetByUserUUID(ctx, userUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,9 +80,9 @@ func (s *Student) GetByUserUUID(userUUID string) (*dto.StudentResponse, error) {
 	}, nil
 }
 
-func (s *Student) Update(uuid string, req *dto.StudentRequest) (*dto.StudentResponse, error) {
+func (s *Student) Update(ctx context.Context, uuid string, req *dto.StudentRequest) (*dto.StudentResponse, error) {
 	// First get existing student
-	existing, err := s.repo.GetStudent().GetByUUID(uuid)
+	existing, err := s.repo.GetStudent().GetByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -86,8 +90,8 @@ func (s *Student) Update(uuid string, req *dto.StudentRequest) (*dto.StudentResp
 	// Update fields
 	existing.University = req.University
 
-	err = s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetStudent().Update(tx, existing)
+	err = s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetStudent().Update(ctx, tx, existing)
 	})
 
 	if err != nil {
@@ -101,14 +105,14 @@ func (s *Student) Update(uuid string, req *dto.StudentRequest) (*dto.StudentResp
 	}, nil
 }
 
-func (s *Student) Delete(uuid string) error {
-	return s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetStudent().Delete(tx, uuid)
+func (s *Student) Delete(ctx context.Context, uuid string) error {
+	return s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetStudent().Delete(ctx, tx, uuid)
 	})
 }
 
-func (s *Student) GetAll() ([]*dto.StudentResponse, error) {
-	students, err := s.repo.GetStudent().GetAll()
+func (s *Student) GetAll(ctx context.Context) ([]*dto.StudentResponse, error) {
+	students, err := s.repo.GetStudent().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -125,43 +129,47 @@ func (s *Student) GetAll() ([]*dto.StudentResponse, error) {
 	return responses, nil
 }
 
-func (s *Student) AddSkill(studentUUID, skillName string) error {
+// AddSkill adds a skill to the student or, if the student already has it,
+// updates the proficiency and years of experience that the request sets.
+func (s *Student) AddSkill(ctx context.Context, studentUUID string, req *dto.StudentSkillRequest) error {
 	// First, get the skill by name to get its UUID
-	skill, err := s.repo.GetSkill().GetByName(skillName)
+	skill, err := s.repo.GetSkill().GetByName(ctx, req.SkillName)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetStudent().AddSkill(tx, studentUUID, skill.UUID)
+	return s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetStudent().AddSkill(ctx, tx, studentUUID, skill.UUID, req.Proficiency, req.YearsExperience)
 	})
 }
 
-func (s *Student) RemoveSkill(studentUUID, skillName string) error {
+func (s *Student) RemoveSkill(ctx context.Context, studentUUID, skillName string) error {
 	// First, get the skill by name to get its UUID
-	skill, err := s.repo.GetSkill().GetByName(skillName)
+	skill, err := s.repo.GetSkill().GetByName(ctx, skillName)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.WithTransaction(func(tx *sqlx.Tx) error {
-		return s.repo.GetStudent().RemoveSkill(tx, studentUUID, skill.UUID)
+	return s.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return s.repo.GetStudent().RemoveSkill(ctx, tx, studentUUID, skill.UUID)
 	})
 }
 
-func (s *Student) GetSkills(studentUUID string) ([]*dto.SkillResponse, error) {
-	skills, err := s.repo.GetStudent().GetSkills(studentUUID)
+func (s *Student) GetSkills(ctx context.Context, studentUUID string) ([]*dto.StudentSkillResponse, error) {
+	skills, err := s.repo.GetStudent().GetSkills(ctx, studentUUID)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []*dto.SkillResponse
+	var responses []*dto.StudentSkillResponse
 	for _, skill := range skills {
-		responses = append(responses, &dto.SkillResponse{
-			UUID:        skill.UUID,
-			Name:        skill.Name,
-			Description: skill.Description,
-			CreatedAt:   skill.CreatedAt,
+		responses = append(responses, &dto.StudentSkillResponse{
+			UUID:             skill.SkillUUID,
+			Name:             skill.SkillName,
+			Description:      skill.SkillDescription,
+			Proficiency:      skill.Proficiency,
+			YearsExperience:  skill.YearsExperience,
+			EndorsementCount: skill.EndorsementCount,
 		})
 	}
 
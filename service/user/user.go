@@ -3,7 +3,13 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/helper"
 	modelDB "github.com/HPNV/growlink-backend/model/db"
 	modelDTO "github.com/HPNV/growlink-backend/model/dto"
 	"github.com/HPNV/growlink-backend/repository"
@@ -11,24 +17,29 @@ import (
 )
 
 type IUser interface {
-	Login(ctx context.Context, email, password string) (*modelDB.User, error)
+	Login(ctx context.Context, email, password string) (*modelDTO.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*modelDTO.LoginResponse, error)
+	IssueTokens(ctx context.Context, userUUID string) (*modelDTO.LoginResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
 	Register(ctx context.Context, request modelDTO.RegisterRequest) (*modelDB.User, error)
-	GetAll() ([]*modelDTO.UserResponse, error)
+	GetAll(ctx context.Context) ([]*modelDTO.UserResponse, error)
 	GetDetail(ctx context.Context, uuid string) (*modelDTO.UserDetailResponse, error)
-	GetStudentList(req *modelDTO.StudentListRequest) (*modelDTO.StudentListResponse, error)
+	GetStudentList(ctx context.Context, req *modelDTO.StudentListRequest) (*modelDTO.StudentListResponse, error)
 }
 
 type User struct {
 	repo repository.IRegistry
+	auth config.AuthConfig
 }
 
-func NewUser(repo repository.IRegistry) IUser {
+func NewUser(repo repository.IRegistry, auth config.AuthConfig) IUser {
 	return &User{
 		repo: repo,
+		auth: auth,
 	}
 }
 
-func (u *User) Login(ctx context.Context, email, password string) (*modelDB.User, error) {
+func (u *User) Login(ctx context.Context, email, password string) (*modelDTO.LoginResponse, error) {
 	user, err := u.repo.GetUser().Login(
 		ctx,
 		email,
@@ -37,10 +48,167 @@ func (u *User) Login(ctx context.Context, email, password string) (*modelDB.User
 	if err != nil {
 		return nil, err
 	}
-	return user, nil
+
+	var response *modelDTO.LoginResponse
+	err = u.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		response, _, err = u.issueTokens(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *User) Refresh(ctx context.Context, refreshToken string) (*modelDTO.LoginResponse, error) {
+	stored, err := u.repo.GetToken().GetByHash(ctx, helper.HashToken(refreshToken))
+	if err != nil {
+		return nil, constant.ErrInvalidRefreshToken
+	}
+
+	// A revoked token being presented again means it was leaked or replayed,
+	// so every session of that user is terminated.
+	if stored.RevokedAt != nil {
+		u.revokeAll(ctx, stored.UserUUID)
+		return nil, constant.ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, constant.ErrInvalidRefreshToken
+	}
+
+	user, err := u.repo.GetUser().GetByUUID(ctx, stored.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *modelDTO.LoginResponse
+	err = u.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		var replacedBy string
+		response, replacedBy, err = u.issueTokens(ctx, tx, user)
+		if err != nil {
+			return err
+		}
+		return u.repo.GetToken().Revoke(ctx, tx, stored.UUID, &replacedBy)
+	})
+	// Losing the race to rotate the token is a replay as well
+	if errors.Is(err, constant.ErrInvalidRefreshToken) {
+		u.revokeAll(ctx, stored.UserUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// IssueTokens starts a new session for a signed-in user whose profiles
+// changed, since the claims of their current access token predate them.
+func (u *User) IssueTokens(ctx context.Context, userUUID string) (*modelDTO.LoginResponse, error) {
+	user, err := u.repo.GetUser().GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *modelDTO.LoginResponse
+	err = u.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		response, _, err = u.issueTokens(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *User) revokeAll(ctx context.Context, userUUID string) {
+	err := u.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return u.repo.GetToken().RevokeAllByUser(ctx, tx, userUUID)
+	})
+	if err != nil {
+		log.Printf("Failed to revoke refresh tokens of user %s: %v", userUUID, err)
+	}
+}
+
+func (u *User) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := u.repo.GetToken().GetByHash(ctx, helper.HashToken(refreshToken))
+	if err != nil {
+		return constant.ErrInvalidRefreshToken
+	}
+
+	err = u.repo.WithTransaction(ctx, func(tx *sqlx.Tx) error {
+		return u.repo.GetToken().Revoke(ctx, tx, stored.UUID, nil)
+	})
+	// Logging out twice is not an error
+	if errors.Is(err, constant.ErrInvalidRefreshToken) {
+		return nil
+	}
+	return err
+}
+
+// issueTokens signs a new access token and persists a new refresh token,
+// returning the response together with the UUID of the stored refresh token.
+func (u *User) issueTokens(ctx context.Context, tx *sqlx.Tx, user *modelDB.User) (*modelDTO.LoginResponse, string, error) {
+	claims := helper.AccessClaims{Role: user.Role}
+	claims.Subject = user.UUID
+
+	switch user.Role {
+	case "student":
+		student, err := u.repo.GetStudent().GetByUserUUID(ctx, user.UUID)
+		if err != nil {
+			return nil, "", err
+		}
+		claims.StudentUUID = student.UUID
+	case "business":
+		business, err := u.repo.GetBusiness().GetByUserUUID(ctx, user.UUID)
+		if err != nil {
+			return nil, "", err
+		}
+		claims.BusinessUUID = business.UUID
+	}
+
+	accessToken, expiresAt, err := helper.GenerateAccessToken(u.auth, claims)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, err := helper.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	stored := &modelDB.RefreshToken{
+		UserUUID:  user.UUID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(u.auth.RefreshTTL),
+	}
+	if err := u.repo.GetToken().Create(ctx, tx, stored); err != nil {
+		return nil, "", err
+	}
+
+	return &modelDTO.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Seconds()),
+		User: modelDTO.UserResponse{
+			UUID:      user.UUID,
+			Email:     user.Email,
+			Name:      user.Name,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt,
+		},
+	}, stored.UUID, nil
 }
 
 func (u *User) Register(ctx context.Context, request modelDTO.RegisterRequest) (*modelDB.User, error) {
+	// Admin accounts are provisioned out of band, never through self-registration.
+	if request.Role == "admin" {
+		return nil, constant.ErrForbidden
+	}
+
 	user := &modelDB.User{
 		Email: request.Email,
 		Name:  request.Name,
@@ -55,25 +223,25 @@ func (u *User) Register(ctx context.Context, request modelDTO.RegisterRequest) (
 		switch user.Role {
 		case "student":
 			if request.University == nil {
-				return errors.New("university is required for student role")
+				return constant.Validation("university_required", "university is required for student role")
 			}
 			student := &modelDB.Student{
 				UserUUID:   userResult.UUID,
 				University: *request.University,
 			}
-			err = u.repo.GetStudent().Create(tx, student)
+			err = u.repo.GetStudent().Create(ctx, tx, student)
 			if err != nil {
 				return err
 			}
 		case "business":
 			if request.CompanyName == nil {
-				return errors.New("company_name is required for business role")
+				return constant.Validation("company_name_required", "company_name is required for business role")
 			}
 			business := &modelDB.Business{
 				UserUUID:    userResult.UUID,
 				CompanyName: *request.CompanyName,
 			}
-			err = u.repo.GetBusiness().Create(tx, business)
+			err = u.repo.GetBusiness().Create(ctx, tx, business)
 			if err != nil {
 				return err
 			}
@@ -84,15 +252,15 @@ func (u *User) Register(ctx context.Context, request modelDTO.RegisterRequest) (
 		return nil
 	}
 
-	err := u.repo.WithTransaction(txFunc)
+	err := u.repo.WithTransaction(ctx, txFunc)
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (u *User) GetAll() ([]*modelDTO.UserResponse, error) {
-	users, err := u.repo.GetUser().GetAll()
+func (u *User) GetAll(ctx context.Context) ([]*modelDTO.UserResponse, error) {
+	users, err := u.repo.GetUser().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -127,13 +295,13 @@ func (u *User) GetDetail(ctx context.Context, uuid string) (*modelDTO.UserDetail
 
 	switch user.Role {
 	case "student":
-		student, err := u.repo.GetStudent().GetByUserUUID(user.UUID)
+		student, err := u.repo.GetStudent().GetByUserUUID(ctx, user.UUID)
 		if err != nil {
 			return nil, err
 		}
 		userDTO.University = &student.University
 
-		skills, err := u.repo.GetSkill().GetByStudentUUID(user.UUID)
+		skills, err := u.repo.GetSkill().GetByStudentUUID(ctx, student.UUID)
 		if err != nil {
 			return nil, err
 		}
@@ -142,7 +310,7 @@ func (u *User) GetDetail(ctx context.Context, uuid string) (*modelDTO.UserDetail
 			userDTO.Skills = append(userDTO.Skills, skill.Name)
 		}
 	case "business":
-		business, err := u.repo.GetBusiness().GetByUserUUID(user.UUID)
+		business, err := u.repo.GetBusiness().GetByUserUUID(ctx, user.UUID)
 		if err != nil {
 			return nil, err
 		}
@@ -152,12 +320,32 @@ func (u *User) GetDetail(ctx context.Context, uuid string) (*modelDTO.UserDetail
 	return &userDTO, nil
 }
 
-func (u *User) GetStudentList(req *modelDTO.StudentListRequest) (*modelDTO.StudentListResponse, error) {
-	users, students, totalCount, err := u.repo.GetUser().GetStudentList(req)
+func (u *User) GetStudentList(ctx context.Context, req *modelDTO.StudentListRequest) (*modelDTO.StudentListResponse, error) {
+	if req.MinProficiency != nil && modelDTO.ProficienciesFrom(*req.MinProficiency) == nil {
+		return nil, constant.Validation("invalid_proficiency", fmt.Sprintf("unknown proficiency %q", *req.MinProficiency))
+	}
+
+	if req.Cursor != nil {
+		after, err := helper.DecodeCursor(*req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		req.After = after
+	}
+
+	users, students, totalCount, err := u.repo.GetUser().GetStudentList(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	var nextCursor *string
+	if req.Cursor != nil && len(users) > req.Limit {
+		users = users[:req.Limit]
+		last := users[len(users)-1]
+		cursor := helper.EncodeCursor(modelDTO.Cursor{Key: last.Email, UUID: last.UUID})
+		nextCursor = &cursor
+	}
+
 	var responses []*modelDTO.StudentDetailResponse
 
 	// Create a map for quick lookup of students by user_uuid
@@ -166,6 +354,16 @@ func (u *User) GetStudentList(req *modelDTO.StudentListRequest) (*modelDTO.Stude
 		studentMap[student.UserUUID] = student
 	}
 
+	studentUUIDs := make([]string, 0, len(students))
+	for _, student := range students {
+		studentUUIDs = append(studentUUIDs, student.UUID)
+	}
+
+	skills, err := u.repo.GetStudent().GetSkillsByStudentUUIDs(ctx, studentUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, user := range users {
 		student := studentMap[user.UUID]
 		if student == nil {
@@ -181,17 +379,30 @@ func (u *User) GetStudentList(req *modelDTO.StudentListRequest) (*modelDTO.Stude
 			CreatedAt:  user.CreatedAt,
 		}
 
-		// Get skills for this student
-		skills, err := u.repo.GetSkill().GetByStudentUUID(student.UUID)
-		if err == nil {
-			for _, skill := range skills {
-				studentResponse.Skills = append(studentResponse.Skills, skill.Name)
-			}
+		for _, skill := range skills[student.UUID] {
+			studentResponse.Skills = append(studentResponse.Skills, skill.SkillName)
+			studentResponse.SkillDetails = append(studentResponse.SkillDetails, &modelDTO.StudentSkillResponse{
+				UUID:             skill.SkillUUID,
+				Name:             skill.SkillName,
+				Description:      skill.SkillDescription,
+				Proficiency:      skill.Proficiency,
+				YearsExperience:  skill.YearsExperience,
+				EndorsementCount: skill.EndorsementCount,
+			})
+			studentResponse.EndorsementCount += skill.EndorsementCount
 		}
 
 		responses = append(responses, studentResponse)
 	}
 
+	if req.Cursor != nil {
+		return &modelDTO.StudentListResponse{
+			Students:   responses,
+			Limit:      req.Limit,
+			NextCursor: nextCursor,
+		}, nil
+	}
+
 	// Calculate total pages
 	totalPages := totalCount / req.Limit
 	if totalCount%req.Limit != 0 {
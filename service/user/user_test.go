@@ -0,0 +1,199 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+	"github.com/HPNV/growlink-backend/constant"
+	"github.com/HPNV/growlink-backend/internal/sqltest"
+	modelDTO "github.com/HPNV/growlink-backend/model/dto"
+)
+
+const skillsPerStudent = 3
+
+// fakeStudents answers the student list queries with n students, each with
+// skillsPerStudent skills.
+func fakeStudents(n int) sqltest.Responder {
+	return func(query string, args []driver.NamedValue) *sqltest.Rows {
+		switch {
+		case strings.Contains(query, "COUNT(*)") && !strings.Contains(query, "skill_endorsements"):
+			return &sqltest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(n)}}}
+
+		case strings.Contains(query, "ss.student_uuid = ANY"):
+			rows := &sqltest.Rows{Columns: []string{"student_uuid", "skill_uuid", "skill_name", "skill_description", "proficiency", "years_experience", "endorsement_count"}}
+			for i := 0; i < n; i++ {
+				for j := 0; j < skillsPerStudent; j++ {
+					rows.Values = append(rows.Values, []driver.Value{
+						fmt.Sprintf("student-%d", i), fmt.Sprintf("skill-%d", j), fmt.Sprintf("Skill %d", j), "", "beginner", int64(1), int64(0),
+					})
+				}
+			}
+			return rows
+
+		case strings.Contains(query, "FROM students s"):
+			rows := &sqltest.Rows{Columns: []string{"uuid", "user_uuid", "university"}}
+			for i := 0; i < n; i++ {
+				rows.Values = append(rows.Values, []driver.Value{fmt.Sprintf("student-%d", i), fmt.Sprintf("user-%d", i), "University"})
+			}
+			return rows
+
+		case strings.Contains(query, "FROM users u"):
+			rows := &sqltest.Rows{Columns: []string{"uuid", "email", "name", "role", "created_at"}}
+			for i := 0; i < n; i++ {
+				rows.Values = append(rows.Values, []driver.Value{fmt.Sprintf("user-%d", i), fmt.Sprintf("user%d@example.com", i), "Student", "student", "2025-01-01T00:00:00Z"})
+			}
+			return rows
+		}
+		return nil
+	}
+}
+
+func newService(n int) (IUser, *sqltest.Recorder) {
+	recorder := sqltest.Open(fakeStudents(n))
+	return NewUser(recorder.Registry(nil), config.AuthConfig{}), recorder
+}
+
+func listRequest(n int, cursor bool) *modelDTO.StudentListRequest {
+	req := &modelDTO.StudentListRequest{Page: 1, Limit: n}
+	if cursor {
+		empty := ""
+		req.Cursor = &empty
+	}
+	return req
+}
+
+func TestGetStudentListQueryCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  bool
+		queries int
+	}{
+		{"page", false, 4},
+		{"cursor", true, 3},
+	}
+
+	for _, tt := range tests {
+		for _, n := range []int{1, 10, 100} {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, n), func(t *testing.T) {
+				service, recorder := newService(n)
+
+				response, err := service.GetStudentList(context.Background(), listRequest(n, tt.cursor))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				skills := 0
+				for _, student := range response.Students {
+					skills += len(student.SkillDetails)
+				}
+				if len(response.Students) != n || skills != n*skillsPerStudent {
+					t.Errorf("got %d students with %d skills, want %d with %d", len(response.Students), skills, n, n*skillsPerStudent)
+				}
+				if got := recorder.Count(); got != tt.queries {
+					t.Errorf("ran %d queries, want %d:\n%s", got, tt.queries, strings.Join(recorder.Queries(), "\n"))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGetStudentList(b *testing.B) {
+	const n = 100
+
+	for _, cursor := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cursor=%t", cursor), func(b *testing.B) {
+			service, recorder := newService(n)
+			ctx := context.Background()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := service.GetStudentList(ctx, listRequest(n, cursor)); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(recorder.Count())/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// fakeToken answers the refresh token lookup with a token of an admin, and
+// its rotation with rotated affected rows.
+func fakeToken(revoked, expired bool, rotated int64) sqltest.Responder {
+	return func(query string, args []driver.NamedValue) *sqltest.Rows {
+		switch {
+		case strings.Contains(query, "FROM refresh_tokens"):
+			expiresAt := time.Now().Add(time.Hour)
+			if expired {
+				expiresAt = time.Now().Add(-time.Hour)
+			}
+			var revokedAt any
+			if revoked {
+				revokedAt = time.Now().Add(-time.Minute)
+			}
+			return &sqltest.Rows{
+				Columns: []string{"uuid", "user_uuid", "token_hash", "expires_at", "revoked_at", "replaced_by", "created_at"},
+				Values:  [][]driver.Value{{"token", "user", "hash", expiresAt, revokedAt, nil, "2025-01-01T00:00:00Z"}},
+			}
+
+		case strings.Contains(query, "FROM users u"):
+			return &sqltest.Rows{
+				Columns: []string{"uuid", "email", "name", "role", "created_at"},
+				Values:  [][]driver.Value{{"user", "admin@example.com", "Admin", "admin", "2025-01-01T00:00:00Z"}},
+			}
+
+		case strings.Contains(query, "INSERT INTO refresh_tokens"):
+			return &sqltest.Rows{Columns: []string{"uuid", "created_at"}, Values: [][]driver.Value{{"new-token", "2025-01-01T00:00:00Z"}}}
+
+		case strings.Contains(query, "WHERE uuid = $1 AND revoked_at IS NULL"):
+			return &sqltest.Rows{Affected: rotated}
+		}
+		return nil
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		revoked   bool
+		expired   bool
+		rotated   int64
+		err       error
+		revokeAll bool
+	}{
+		{"valid token", false, false, 1, nil, false},
+		{"revoked token", true, false, 1, constant.ErrInvalidRefreshToken, true},
+		{"expired token", false, true, 1, constant.ErrInvalidRefreshToken, false},
+		{"rotation lost the race", false, false, 0, constant.ErrInvalidRefreshToken, true},
+	}
+
+	auth := config.AuthConfig{Secret: "secret", Issuer: "growlink", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sqltest.Open(fakeToken(tt.revoked, tt.expired, tt.rotated))
+			response, err := NewUser(recorder.Registry(nil), auth).Refresh(context.Background(), "refresh-token")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Refresh() = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && (response == nil || response.RefreshToken == "refresh-token") {
+				t.Errorf("Refresh() did not issue a new refresh token")
+			}
+
+			revokedAll := false
+			for _, query := range recorder.Queries() {
+				if strings.Contains(query, "WHERE user_uuid = $1 AND revoked_at IS NULL") {
+					revokedAll = true
+				}
+			}
+			if revokedAll != tt.revokeAll {
+				t.Errorf("revoked all sessions = %v, want %v", revokedAll, tt.revokeAll)
+			}
+		})
+	}
+}
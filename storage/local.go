@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local keeps objects as files under a directory that the server publishes
+// at a URL prefix. It only works when a single instance serves uploads.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocal(dir, baseURL string) IStorage {
+	return &Local{
+		dir:     dir,
+		baseURL: baseURL,
+	}
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err := io.Copy(dst, r); err != nil {
+		dst.Close()
+		os.Remove(filePath)
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return dst.Close()
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// Presign returns the public URL of the file; local files never expire.
+func (l *Local) Presign(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(l.baseURL, "/") + "/" + key, nil
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+)
+
+const (
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3Service        = "s3"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3TimeFormat     = "20060102T150405Z"
+	s3DateFormat     = "20060102"
+	s3MaxPresignTime = 7 * 24 * time.Hour
+)
+
+// S3 stores objects in an S3-compatible bucket, signing requests with
+// Signature Version 4. Path-style addressing lets it talk to MinIO and other
+// stand-ins that do not resolve bucket subdomains.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3(cfg config.StorageConfig) (IStorage, error) {
+	endpoint, err := url.Parse(cfg.S3Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.S3Endpoint)
+	}
+
+	return &S3{
+		endpoint:  endpoint,
+		region:    cfg.S3Region,
+		bucket:    cfg.S3Bucket,
+		accessKey: cfg.S3AccessKey,
+		secretKey: cfg.S3SecretKey,
+		pathStyle: cfg.S3PathStyle,
+		client:    &http.Client{Transport: s3Transport()},
+		now:       time.Now,
+	}, nil
+}
+
+// s3Transport bounds each phase of a request rather than the whole of it, so
+// large uploads and slow downloads run as long as their context allows while
+// an unresponsive endpoint still fails fast.
+func s3Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = time.Minute
+	return transport
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Presign returns a query-signed GET URL. S3 caps the lifetime at seven days.
+func (s *S3) Presign(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if expiry <= 0 || expiry > s3MaxPresignTime {
+		expiry = s3MaxPresignTime
+	}
+
+	u := s.objectURL(key)
+	now := s.now().UTC()
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, scope, canonical))
+	u.RawQuery = canonicalQuery(query)
+
+	return u.String(), nil
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req)
+	return req, nil
+}
+
+// do sends a signed request and turns error responses into errors, closing
+// their bodies.
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("storage: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(detail)))
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	objectPath := "/" + key
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = escape(u.Path, false)
+	return &u
+}
+
+// sign adds header-based SigV4 authentication to req. The body is sent
+// unsigned so uploads can stream without being buffered to hash them.
+func (s *S3) sign(req *http.Request) {
+	now := s.now().UTC()
+	scope := s.scope(now)
+
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedBody)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + s3UnsignedBody + "\n" +
+		"x-amz-date:" + now.Format(s3TimeFormat) + "\n"
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedBody,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, s.signature(now, scope, canonical)))
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format(s3DateFormat) + "/" + s.region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *S3) signature(now time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + now.Format(s3TimeFormat) + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(s3DateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as SigV4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, escape(key, true)+"="+escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape percent-encodes everything except the unreserved characters, and
+// slashes too when encodeSlash is set.
+func escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
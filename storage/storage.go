@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/HPNV/growlink-backend/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// IStorage stores uploaded files as objects addressed by slash-separated
+// keys such as "images/<uuid>.png".
+type IStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound when no object exists under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when the object is already gone.
+	Delete(ctx context.Context, key string) error
+	// Presign returns a URL from which the object can be downloaded for at
+	// least expiry. Drivers serving public files may ignore expiry.
+	Presign(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New returns the driver selected by cfg.Driver.
+func New(cfg config.StorageConfig) (IStorage, error) {
+	switch cfg.Driver {
+	case DriverLocal:
+		return NewLocal(cfg.LocalDir, cfg.LocalURL), nil
+	case DriverS3:
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+func validKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	return nil
+}